@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/hajimehoshi/ebiten v1.12.13
 	github.com/hajimehoshi/ebiten/v2 v2.9.7
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/image v0.34.0 // indirect
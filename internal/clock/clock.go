@@ -0,0 +1,47 @@
+// Package clock drives the fixed-timestep simulation from a variable
+// frame rate. Frames report how much real time passed; the clock hands
+// that time out as whole simulation steps of a fixed length, carrying the
+// remainder over to the next frame.
+package clock
+
+// maxStepsPerFrame caps the steps run for one frame at normal speed so a
+// long stall (a dragged window, a breakpoint) does not try to catch up all
+// at once. The cap grows with the game speed.
+const maxStepsPerFrame = 8
+
+// Clock is a fixed-step accumulator
+type Clock struct {
+	StepsPerSecond int
+	accumulated    float64 // Seconds received but not yet handed out as steps
+}
+
+// New creates a clock handing out stepsPerSecond steps per second of
+// frame time
+func New(stepsPerSecond int) *Clock {
+	return &Clock{StepsPerSecond: max(stepsPerSecond, 1)}
+}
+
+// Step returns the length of one step in seconds
+func (c *Clock) Step() float64 {
+	return 1 / float64(c.StepsPerSecond)
+}
+
+// Advance adds elapsed seconds of frame time played at speed times the
+// normal rate and returns how many steps are due. Time left over once the
+// cap for that speed is reached is dropped.
+func (c *Clock) Advance(elapsed float64, speed int) int {
+	c.accumulated += elapsed * float64(speed)
+	step := c.Step()
+	limit := maxStepsPerFrame * max(speed, 1)
+
+	steps := 0
+	for c.accumulated >= step && steps < limit {
+		c.accumulated -= step
+		steps++
+	}
+	if c.accumulated >= step {
+		// Still behind after a full frame's worth: a stall, not worth catching up
+		c.accumulated = 0
+	}
+	return steps
+}
@@ -0,0 +1,31 @@
+package clock
+
+import "testing"
+
+// A 20 FPS display or a raised tick rate hands out more than
+// maxStepsPerFrame steps per frame at 4x
+func TestAdvanceKeepsUpAtEverySpeed(t *testing.T) {
+	for _, fps := range []int{60, 20} {
+		for _, speed := range []int{1, 2, 4} {
+			c := New(60)
+			steps := 0
+			for i := 0; i < fps; i++ {
+				steps += c.Advance(1/float64(fps), speed)
+			}
+			// Rounding may leave the last step in the accumulator
+			if want := 60 * speed; steps < want-1 || steps > want {
+				t.Errorf("%d FPS at %dx: %d steps in one second, want %d", fps, speed, steps, want)
+			}
+		}
+	}
+}
+
+func TestAdvanceDropsStalls(t *testing.T) {
+	c := New(60)
+	if steps := c.Advance(10, 2); steps != 2*maxStepsPerFrame {
+		t.Errorf("got %d steps for a stall, want the cap of %d", steps, 2*maxStepsPerFrame)
+	}
+	if steps := c.Advance(0, 2); steps != 0 {
+		t.Errorf("stall time carried over into %d steps", steps)
+	}
+}
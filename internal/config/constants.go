@@ -15,42 +15,50 @@ const (
 	MapOffsetY  float32 = 120
 )
 
-// Window holds the game window configuration
+// Window holds the game window configuration. Width and Height are the
+// logical screen size every screen is laid out for, so they are fixed;
+// the settings change the window scale instead.
 type Window struct {
 	Width  int
 	Height int
 	Title  string
+	TPS    int // Ebiten updates per second; gameplay speed does not depend on it
 }
 
-// Config is the default window configuration
+// Config is the window configuration. Load can override it at startup.
 var Config = Window{
 	Width:  800,
 	Height: 720,
 	Title:  "Final Path v1.0",
+	TPS:    60,
 }
 
 type Constants struct {
 	TowerLimit         int
 	InitialLives       int
 	InitialTowerCost   int
-	InitialTowerRefund int
+	TowerSellRate      float32 // Share of the coins invested in a tower refunded when selling it
 	InitialCoins       int
 	EnemiesDefeated    int
 	DifficultyModifier int
-	SpawnInterval      int
+	TickRate           int     // Fixed simulation steps per second
+	SpawnInterval      float32 // Seconds between spawns of a wave group
 	TowerDamageBoost   int
 	TowerFireRateBoost float32
 }
 
+// GameConstants are the balance values of a match. Load can override them
+// at startup.
 var GameConstants = Constants{
 	TowerLimit:         3,
 	InitialLives:       10,
 	InitialTowerCost:   15,
-	InitialTowerRefund: 10,
+	TowerSellRate:      0.7,
 	InitialCoins:       50,
 	EnemiesDefeated:    0,
 	DifficultyModifier: 1,
-	SpawnInterval:      60,
+	TickRate:           60,
+	SpawnInterval:      1,
 	TowerDamageBoost:   0,
 	TowerFireRateBoost: 1.0,
 }
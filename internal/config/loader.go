@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load
+const EnvPrefix = "FINALPATH_"
+
+// setting is one value of Config or GameConstants that can be changed
+// without recompiling. Its key is used as is in files, as FINALPATH_KEY
+// in the environment and as -key on the command line (see envName and
+// flagName).
+type setting struct {
+	key   string
+	usage string
+	set   func(value string) error // Parses, checks and stores the value
+}
+
+// settings are every loadable setting, in the order -help lists them
+var settings = []setting{
+	stringSetting("title", "window title", &Config.Title),
+	intSetting("tps", "default Ebiten updates per second, until changed in the settings", &Config.TPS, 1),
+	intSetting("towerLimit", "towers that can be built at the start of a match", &GameConstants.TowerLimit, 0),
+	intSetting("initialLives", "lives at the start of a match", &GameConstants.InitialLives, 1),
+	intSetting("initialCoins", "coins at the start of a match", &GameConstants.InitialCoins, 0),
+	intSetting("initialTowerCost", "cost of the first tower before type multipliers", &GameConstants.InitialTowerCost, 0),
+	floatSetting("towerSellRate", "share of a tower's investment refunded when selling it", &GameConstants.TowerSellRate, 0, 1),
+	intSetting("difficultyModifier", "difficulty modifier at the start of a match", &GameConstants.DifficultyModifier, 1),
+	intSetting("tickRate", "fixed simulation steps per second", &GameConstants.TickRate, 1),
+	floatSetting("spawnInterval", "seconds between spawns of a procedural wave", &GameConstants.SpawnInterval, 0.05, 60),
+	intSetting("towerDamageBoost", "damage added to every tower at the start of a match", &GameConstants.TowerDamageBoost, 0),
+	floatSetting("towerFireRateBoost", "fire rate multiplier of every tower at the start of a match", &GameConstants.TowerFireRateBoost, 0.1, 10),
+}
+
+func stringSetting(key, usage string, ptr *string) setting {
+	return setting{key: key, usage: usage, set: func(value string) error {
+		*ptr = value
+		return nil
+	}}
+}
+
+func intSetting(key, usage string, ptr *int, min int) setting {
+	return setting{key: key, usage: usage, set: func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		if n < min {
+			return fmt.Errorf("%d is below the minimum of %d", n, min)
+		}
+		*ptr = n
+		return nil
+	}}
+}
+
+func floatSetting(key, usage string, ptr *float32, min, max float32) setting {
+	return setting{key: key, usage: usage, set: func(value string) error {
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		if float32(f) < min || float32(f) > max {
+			return fmt.Errorf("%v is outside %v to %v", f, min, max)
+		}
+		*ptr = float32(f)
+		return nil
+	}}
+}
+
+func lookupSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// Overrides are setting values by key, as given on the command line
+type Overrides map[string]string
+
+// RegisterFlags adds a flag for every setting to fs. The values are only
+// recorded while parsing; Load applies them last so they win over the
+// file and the environment.
+func RegisterFlags(fs *flag.FlagSet) Overrides {
+	overrides := Overrides{}
+	for _, s := range settings {
+		key := s.key
+		fs.Func(flagName(key), s.usage, func(value string) error {
+			overrides[key] = value
+			return nil
+		})
+	}
+	return overrides
+}
+
+// Load layers the configuration over the built-in defaults: first the
+// file at path (skipped when path is empty), then FINALPATH_* variables
+// from env, then overrides. Unknown keys and invalid values are all
+// reported in the returned error; valid values are applied regardless.
+// FINALPATH_* variables that match no setting are only returned as
+// warnings, since the environment is shared with other programs.
+func Load(path string, env []string, overrides Overrides) (warnings []string, err error) {
+	var errs []error
+
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, apply(path, values, func(key string) string { return fmt.Sprintf("key %q", key) })...)
+	}
+
+	values, unknown := envValues(env)
+	for _, name := range unknown {
+		warnings = append(warnings, fmt.Sprintf("environment: ignoring unknown setting %s", name))
+	}
+	errs = append(errs, apply("environment", values, func(key string) string { return EnvPrefix + envName(key) })...)
+	errs = append(errs, apply("command line", overrides, func(key string) string { return "-" + flagName(key) })...)
+
+	return warnings, errors.Join(errs...)
+}
+
+// apply sets values by key, in key order so errors are reported in a
+// stable order. name returns how the source spells a key.
+func apply(source string, values map[string]string, name func(key string) string) []error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		s, ok := lookupSetting(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %s", source, name(key)))
+			continue
+		}
+		if err := s.set(values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", source, name(key), err))
+		}
+	}
+	return errs
+}
+
+// envValues returns the FINALPATH_* variables of env by setting key and
+// the sorted names of those that match no setting
+func envValues(env []string) (values map[string]string, unknown []string) {
+	values = map[string]string{}
+	for _, entry := range env {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		key := ""
+		for _, s := range settings {
+			if EnvPrefix+envName(s.key) == name {
+				key = s.key
+			}
+		}
+		if key == "" {
+			unknown = append(unknown, name)
+			continue
+		}
+		values[key] = value
+	}
+	sort.Strings(unknown)
+	return values, unknown
+}
+
+// readFile reads a configuration file into values by key. Files ending in
+// .toml are read as TOML, anything else as a JSON object.
+//
+//	{"initialLives": 15, "initialCoins": 80, "title": "Final Path (easy)"}
+//
+//	# TOML
+//	initialLives = 15
+//	initialCoins = 80
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		values, err = parseTOML(data)
+	} else {
+		values, err = parseJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		// Strings are unquoted; numbers are kept as written
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+		values[key] = text
+	}
+	return values, nil
+}
+
+// parseTOML reads the flat subset of TOML the configuration needs:
+// "key = value" lines with # comments, basic strings and numbers. Tables
+// are not supported as every setting is top-level.
+func parseTOML(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", line)
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			unquoted, rest, err := cutQuoted(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after string", line, rest)
+			}
+			value = unquoted
+		} else if comment := strings.Index(value, "#"); comment >= 0 {
+			value = strings.TrimSpace(value[:comment])
+		}
+
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: %q set twice", line, key)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// cutQuoted splits a line value starting with a basic string into the
+// unquoted string and whatever follows it
+func cutQuoted(value string) (string, string, error) {
+	prefix, err := strconv.QuotedPrefix(value)
+	if err != nil {
+		return "", "", fmt.Errorf("bad string %s", value)
+	}
+	unquoted, err := strconv.Unquote(prefix)
+	if err != nil {
+		return "", "", fmt.Errorf("bad string %s", prefix)
+	}
+	return unquoted, strings.TrimSpace(value[len(prefix):]), nil
+}
+
+// envName turns a setting key into its environment variable name without
+// the prefix: towerSellRate becomes TOWER_SELL_RATE
+func envName(key string) string {
+	return strings.ToUpper(splitWords(key, "_"))
+}
+
+// flagName turns a setting key into its flag name: towerSellRate becomes
+// tower-sell-rate
+func flagName(key string) string {
+	return strings.ToLower(splitWords(key, "-"))
+}
+
+// splitWords joins the words of a camelCase key with sep
+func splitWords(key, sep string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteString(sep)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
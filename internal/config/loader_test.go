@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resetConfig restores the built-in values once the test is done, since
+// Load changes the package globals
+func resetConfig(t *testing.T) {
+	t.Helper()
+	window, constants := Config, GameConstants
+	t.Cleanup(func() { Config, GameConstants = window, constants })
+}
+
+// writeFile writes a configuration file into a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayering(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		env       []string
+		overrides Overrides
+		want      int
+	}{
+		{name: "defaults", want: 10},
+		{name: "file", file: `{"initialLives": 15}`, want: 15},
+		{name: "env over file", file: `{"initialLives": 15}`, env: []string{"FINALPATH_INITIAL_LIVES=20"}, want: 20},
+		{
+			name:      "flag over env and file",
+			file:      `{"initialLives": 15}`,
+			env:       []string{"FINALPATH_INITIAL_LIVES=20"},
+			overrides: Overrides{"initialLives": "25"},
+			want:      25,
+		},
+		{name: "flag over file", file: `{"initialLives": 15}`, overrides: Overrides{"initialLives": "25"}, want: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetConfig(t)
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, "config.json", tt.file)
+			}
+
+			warnings, err := Load(path, tt.env, tt.overrides)
+			if err != nil || len(warnings) != 0 {
+				t.Fatalf("got warnings %v, error %v", warnings, err)
+			}
+			if GameConstants.InitialLives != tt.want {
+				t.Errorf("got %d lives, want %d", GameConstants.InitialLives, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		toml      bool // Write file as config.toml instead of config.json
+		env       []string
+		overrides Overrides
+		err       string // Part of the error, "" for none
+		warning   string // Part of the only warning, "" for none
+	}{
+		{name: "unknown file key", file: `{"nope": 1}`, err: `unknown setting key "nope"`},
+		{name: "unknown flag key", overrides: Overrides{"nope": "1"}, err: "command line: unknown setting -nope"},
+		{name: "unknown variable", env: []string{"FINALPATH_NOPE=1"}, warning: "FINALPATH_NOPE"},
+		{name: "variables without the prefix", env: []string{"NOPE=1", "PATH=/bin"}},
+		{name: "int below the minimum", file: `{"initialLives": 0}`, err: "below the minimum of 1"},
+		{name: "not a whole number", env: []string{"FINALPATH_TOWER_LIMIT=2.5"}, err: `"2.5" is not a whole number`},
+		{name: "float out of range", overrides: Overrides{"towerSellRate": "1.5"}, err: "1.5 is outside 0 to 1"},
+		{name: "not a number", file: `{"spawnInterval": "soon"}`, err: `"soon" is not a number`},
+		{name: "duplicate TOML key", file: "initialLives = 5\ninitialLives = 6\n", toml: true, err: `line 2: "initialLives" set twice`},
+		{name: "TOML table", file: "[game]\ninitialLives = 5\n", toml: true, err: "line 1: tables are not supported"},
+		{name: "TOML line without a value", file: "initialLives\n", toml: true, err: "line 1: expected key = value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetConfig(t)
+			path := ""
+			if tt.toml {
+				path = writeFile(t, "config.toml", tt.file)
+			} else if tt.file != "" {
+				path = writeFile(t, "config.json", tt.file)
+			}
+
+			warnings, err := Load(path, tt.env, tt.overrides)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+
+			if tt.warning == "" {
+				if len(warnings) != 0 {
+					t.Errorf("unexpected warnings %v", warnings)
+				}
+			} else if len(warnings) != 1 || !strings.Contains(warnings[0], tt.warning) {
+				t.Errorf("got warnings %v, want one containing %q", warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	resetConfig(t)
+	path := writeFile(t, "config.toml", `# Balance for the easy build
+title = "Final Path # easy" # the # inside the quotes is kept
+initialLives = 15 # comment after a number
+towerSellRate = 0.5
+`)
+
+	if _, err := Load(path, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if Config.Title != "Final Path # easy" {
+		t.Errorf("got title %q", Config.Title)
+	}
+	if GameConstants.InitialLives != 15 || GameConstants.TowerSellRate != 0.5 {
+		t.Errorf("got lives %d, sell rate %v; want 15, 0.5", GameConstants.InitialLives, GameConstants.TowerSellRate)
+	}
+}
+
+func TestLoadKeepsValidValues(t *testing.T) {
+	resetConfig(t)
+	_, err := Load("", nil, Overrides{"initialLives": "12", "initialCoins": "-5"})
+	if err == nil {
+		t.Fatal("negative coins accepted")
+	}
+	if GameConstants.InitialLives != 12 {
+		t.Errorf("valid value not applied next to an invalid one: %d lives", GameConstants.InitialLives)
+	}
+}
+
+func TestSettingNames(t *testing.T) {
+	tests := []struct {
+		key, env, flag string
+	}{
+		{key: "towerSellRate", env: "TOWER_SELL_RATE", flag: "tower-sell-rate"},
+		{key: "tps", env: "TPS", flag: "tps"},
+		{key: "initialTowerCost", env: "INITIAL_TOWER_COST", flag: "initial-tower-cost"},
+	}
+
+	for _, tt := range tests {
+		if got := envName(tt.key); got != tt.env {
+			t.Errorf("envName(%q) = %q, want %q", tt.key, got, tt.env)
+		}
+		if got := flagName(tt.key); got != tt.flag {
+			t.Errorf("flagName(%q) = %q, want %q", tt.key, got, tt.flag)
+		}
+	}
+}
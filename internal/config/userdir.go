@@ -0,0 +1,16 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// UserDir returns the directory the game keeps its files in (saves and
+// scores), inside the user's config directory
+func UserDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "finalpath"), nil
+}
@@ -0,0 +1,92 @@
+// Package difficulty holds the presets picked on the map select screen.
+// A preset scales the starting lives and coins, enemy life, speed and
+// bounty, and how quickly the difficulty modifier ramps up.
+package difficulty
+
+// Kind identifies a preset in the Presets registry
+type Kind string
+
+const (
+	Easy      Kind = "easy"
+	Normal    Kind = "normal"
+	Hard      Kind = "hard"
+	Nightmare Kind = "nightmare"
+)
+
+// Kinds lists the presets in the order the map select screen shows them
+var Kinds = []Kind{Easy, Normal, Hard, Nightmare}
+
+// Preset holds the multipliers applied to a match. Normal leaves the
+// configured game constants unchanged.
+type Preset struct {
+	Name       string
+	Lives      float32 // Multiplier on the starting lives
+	Coins      float32 // Multiplier on the starting coins
+	EnemyLife  float32 // Multiplier on the life of every spawned enemy
+	EnemySpeed float32 // Multiplier on the speed of every spawned enemy
+	Bounty     float32 // Multiplier on the coins awarded per kill
+	RampEvery  int     // Waves between increases of the difficulty modifier
+}
+
+// Presets is the registry of every difficulty preset
+var Presets = map[Kind]Preset{
+	Easy: {
+		Name:       "Easy",
+		Lives:      1.5,
+		Coins:      1.5,
+		EnemyLife:  0.75,
+		EnemySpeed: 0.9,
+		Bounty:     1.25,
+		RampEvery:  7,
+	},
+	Normal: {
+		Name:       "Normal",
+		Lives:      1,
+		Coins:      1,
+		EnemyLife:  1,
+		EnemySpeed: 1,
+		Bounty:     1,
+		RampEvery:  5,
+	},
+	Hard: {
+		Name:       "Hard",
+		Lives:      0.7,
+		Coins:      0.8,
+		EnemyLife:  1.3,
+		EnemySpeed: 1.1,
+		Bounty:     0.9,
+		RampEvery:  4,
+	},
+	Nightmare: {
+		Name:       "Nightmare",
+		Lives:      0.5,
+		Coins:      0.6,
+		EnemyLife:  1.7,
+		EnemySpeed: 1.2,
+		Bounty:     0.75,
+		RampEvery:  3,
+	},
+}
+
+// Lookup returns the registered preset for kind
+func Lookup(kind Kind) (Preset, bool) {
+	preset, ok := Presets[kind]
+	return preset, ok
+}
+
+// Name returns the display name of kind. Runs recorded before presets
+// existed have no kind and were played on Normal.
+func Name(kind Kind) string {
+	if kind == "" {
+		kind = Normal
+	}
+	if preset, ok := Presets[kind]; ok {
+		return preset.Name
+	}
+	return string(kind)
+}
+
+// Scale multiplies n by factor, rounding to the nearest whole number
+func Scale(n int, factor float32) int {
+	return int(float32(n)*factor + 0.5)
+}
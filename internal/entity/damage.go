@@ -0,0 +1,43 @@
+package entity
+
+// DamageType decides which of an enemy's defences apply to a hit
+type DamageType string
+
+const (
+	DamagePhysical DamageType = "physical" // Reduced by armor, then resistance
+	DamageMagic    DamageType = "magic"
+	DamageFire     DamageType = "fire"
+	DamageIce      DamageType = "ice"
+	DamageTrue     DamageType = "true" // Ignores armor and resistances
+)
+
+// CriticalMultiplier scales the amount of critical hits
+const CriticalMultiplier = 2
+
+// Damage is a packet of damage dealt to an enemy
+type Damage struct {
+	Amount   float32
+	Type     DamageType
+	Critical bool
+	Source   *Tower // Credited with the damage and the kill, nil for none
+}
+
+// ResolveDamage returns how much life a packet takes from the enemy
+// before its shield. Criticals multiply the amount, armor (after shred)
+// is subtracted from physical damage and the archetype's resistance for
+// the type scales the rest. True damage skips both.
+func (e *Enemy) ResolveDamage(d Damage) float32 {
+	amount := d.Amount
+	if d.Critical {
+		amount *= CriticalMultiplier
+	}
+
+	switch d.Type {
+	case DamageTrue:
+		return amount
+	case DamagePhysical:
+		amount -= float32(e.EffectiveArmor())
+	}
+
+	return max(amount*(1-e.Type().Resistances[d.Type]), 0)
+}
@@ -0,0 +1,207 @@
+package entity
+
+import "image/color"
+
+// EffectKind identifies a status effect in the EffectTypes registry
+type EffectKind string
+
+const (
+	EffectSlow   EffectKind = "slow"
+	EffectPoison EffectKind = "poison"
+	EffectBurn   EffectKind = "burn"
+	EffectStun   EffectKind = "stun"
+	EffectShred  EffectKind = "shred"
+)
+
+// StackRule decides what happens when an effect is applied to an enemy
+// that already has it
+type StackRule int
+
+const (
+	// StackRefresh keeps one instance with the strongest Strength and the
+	// longest remaining duration
+	StackRefresh StackRule = iota
+	// StackIntensity keeps one instance, adds a stack (up to MaxStacks)
+	// and restarts the duration
+	StackIntensity
+	// StackIndependent keeps every application as its own instance
+	StackIndependent
+)
+
+// Effect is a status effect applied to an enemy. The meaning of Strength
+// depends on the kind; see EffectTypes.
+type Effect struct {
+	Kind     EffectKind
+	Strength float32
+	Duration float32 // Seconds left
+	Stacks   int
+	Source   *Tower  // Tower credited with damage over time, nil for none
+	carry    float32 // Fractional damage dealt but not yet applied
+}
+
+// EffectType holds the behaviour shared by every effect of a kind.
+// Nil hooks are skipped.
+type EffectType struct {
+	Name       string
+	Stacking   StackRule
+	MaxStacks  int        // StackIntensity only
+	DamageType DamageType // Type of the damage dealt by OnTick
+	Color      color.RGBA
+	OnTick     func(e *Enemy, fx *Effect, dt float32) // Called every tick of dt seconds while active
+	Speed      func(fx *Effect) float32               // Speed multiplier while active
+	Armor      func(fx *Effect) int                   // Armor removed while active
+}
+
+// EffectTypes is the registry of every status effect
+var EffectTypes = map[EffectKind]EffectType{
+	EffectSlow: {
+		Name:     "Slow",
+		Stacking: StackRefresh,
+		Color:    color.RGBA{120, 200, 255, 255},
+		// Strength is the fraction of speed removed
+		Speed: func(fx *Effect) float32 { return 1 - fx.Strength },
+	},
+	EffectPoison: {
+		Name:       "Poison",
+		Stacking:   StackIntensity,
+		MaxStacks:  5,
+		DamageType: DamageMagic,
+		Color:      color.RGBA{120, 255, 0, 255},
+		// Strength is damage per second per stack; OnTick is set in init
+	},
+	EffectBurn: {
+		Name:       "Burn",
+		Stacking:   StackRefresh,
+		DamageType: DamageFire,
+		Color:      color.RGBA{255, 80, 0, 255},
+		// Strength is damage per second; OnTick is set in init
+	},
+	EffectStun: {
+		Name:     "Stun",
+		Stacking: StackRefresh,
+		Color:    color.RGBA{255, 255, 255, 255},
+		Speed:    func(fx *Effect) float32 { return 0 },
+	},
+	EffectShred: {
+		Name:      "Armor Shred",
+		Stacking:  StackIntensity,
+		MaxStacks: 3,
+		Color:     color.RGBA{200, 0, 200, 255},
+		// Strength is armor removed per stack
+		Armor: func(fx *Effect) int { return int(fx.Strength) * fx.Stacks },
+	},
+}
+
+// The damage over time hooks resolve damage through EffectTypes, so they
+// are attached once the registry exists to avoid an initialization cycle
+func init() {
+	poison := EffectTypes[EffectPoison]
+	poison.OnTick = func(e *Enemy, fx *Effect, dt float32) { e.damageOverTime(fx, fx.Strength*float32(fx.Stacks), dt) }
+	EffectTypes[EffectPoison] = poison
+
+	burn := EffectTypes[EffectBurn]
+	burn.OnTick = func(e *Enemy, fx *Effect, dt float32) { e.damageOverTime(fx, fx.Strength, dt) }
+	EffectTypes[EffectBurn] = burn
+}
+
+// Type returns the effect's type
+func (fx *Effect) Type() EffectType {
+	return EffectTypes[fx.Kind]
+}
+
+// ApplyEffect adds a status effect following its type's stacking rule.
+// Unknown kinds are ignored.
+func (e *Enemy) ApplyEffect(fx Effect) {
+	effectType, ok := EffectTypes[fx.Kind]
+	if !ok || !e.IsAlive() {
+		return
+	}
+	fx.Stacks = max(fx.Stacks, 1)
+
+	if effectType.Stacking != StackIndependent {
+		for i := range e.Effects {
+			current := &e.Effects[i]
+			if current.Kind != fx.Kind {
+				continue
+			}
+
+			switch effectType.Stacking {
+			case StackRefresh:
+				current.Strength = max(current.Strength, fx.Strength)
+				current.Duration = max(current.Duration, fx.Duration)
+			case StackIntensity:
+				current.Stacks = min(current.Stacks+fx.Stacks, max(effectType.MaxStacks, 1))
+				current.Strength = max(current.Strength, fx.Strength)
+				current.Duration = fx.Duration
+			}
+			// The latest tower to reapply the effect gets the credit
+			if fx.Source != nil {
+				current.Source = fx.Source
+			}
+			return
+		}
+	}
+
+	e.Effects = append(e.Effects, fx)
+}
+
+// UpdateEffects runs every active effect's per-tick hook and removes the
+// ones that expired. Called once per tick of dt seconds.
+func (e *Enemy) UpdateEffects(dt float32) {
+	active := e.Effects[:0]
+	for i := range e.Effects {
+		fx := &e.Effects[i]
+		if onTick := fx.Type().OnTick; onTick != nil && e.IsAlive() {
+			onTick(e, fx, dt)
+		}
+
+		fx.Duration -= dt
+		if fx.Duration > 0 {
+			active = append(active, *fx)
+		}
+	}
+	e.Effects = active
+}
+
+// HasEffect reports whether an effect of the given kind is active
+func (e *Enemy) HasEffect(kind EffectKind) bool {
+	for _, fx := range e.Effects {
+		if fx.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SpeedMultiplier returns the combined speed modifier of active effects
+func (e *Enemy) SpeedMultiplier() float32 {
+	multiplier := float32(1)
+	for i := range e.Effects {
+		if speed := e.Effects[i].Type().Speed; speed != nil {
+			multiplier *= max(speed(&e.Effects[i]), 0)
+		}
+	}
+	return multiplier
+}
+
+// EffectiveArmor returns the enemy's armor after armor-reducing effects
+func (e *Enemy) EffectiveArmor() int {
+	armor := e.Armor
+	for i := range e.Effects {
+		if reduce := e.Effects[i].Type().Armor; reduce != nil {
+			armor -= reduce(&e.Effects[i])
+		}
+	}
+	return max(armor, 0)
+}
+
+// damageOverTime deals perSecond damage of the effect's damage type for
+// a tick of dt seconds, carrying fractions over between ticks
+func (e *Enemy) damageOverTime(fx *Effect, perSecond, dt float32) {
+	fx.carry += e.ResolveDamage(Damage{Amount: perSecond * dt, Type: fx.Type().DamageType, Source: fx.Source})
+	damage := int(fx.carry)
+	fx.carry -= float32(damage)
+	if damage > 0 {
+		e.loseLife(damage, fx.Source)
+	}
+}
@@ -0,0 +1,189 @@
+package entity
+
+import "testing"
+
+// effectIndependent is registered by the tests only: no built-in effect
+// keeps independent instances
+const effectIndependent EffectKind = "test-independent"
+
+func init() {
+	EffectTypes[effectIndependent] = EffectType{Name: "Independent", Stacking: StackIndependent}
+}
+
+// newTestEnemy returns a grunt with plenty of life and no movement
+func newTestEnemy() *Enemy {
+	return &Enemy{Kind: EnemyBasic, Life: 100, MaxLife: 100}
+}
+
+func TestApplyEffectStacking(t *testing.T) {
+	tests := []struct {
+		name      string
+		applied   []Effect
+		instances int
+		stacks    int     // Of the first instance
+		strength  float32 // Of the first instance
+		duration  float32 // Of the first instance
+	}{
+		{
+			name:      "refresh keeps the longest duration and one stack",
+			applied:   []Effect{{Kind: EffectSlow, Strength: 0.5, Duration: 1}, {Kind: EffectSlow, Strength: 0.25, Duration: 2}},
+			instances: 1, stacks: 1, strength: 0.5, duration: 2,
+		},
+		{
+			name:      "refresh does not shorten the duration",
+			applied:   []Effect{{Kind: EffectSlow, Strength: 0.5, Duration: 2}, {Kind: EffectSlow, Strength: 0.5, Duration: 1}},
+			instances: 1, stacks: 1, strength: 0.5, duration: 2,
+		},
+		{
+			name:      "intensity adds stacks and restarts the duration",
+			applied:   []Effect{{Kind: EffectPoison, Strength: 1, Duration: 2}, {Kind: EffectPoison, Strength: 1, Duration: 1}},
+			instances: 1, stacks: 2, strength: 1, duration: 1,
+		},
+		{
+			name: "intensity clamps at MaxStacks",
+			applied: []Effect{
+				{Kind: EffectShred, Strength: 2, Duration: 5}, {Kind: EffectShred, Strength: 2, Duration: 5},
+				{Kind: EffectShred, Strength: 2, Duration: 5}, {Kind: EffectShred, Strength: 2, Duration: 5},
+				{Kind: EffectShred, Strength: 2, Duration: 5},
+			},
+			instances: 1, stacks: 3, strength: 2, duration: 5,
+		},
+		{
+			name:      "intensity clamps stacks added at once",
+			applied:   []Effect{{Kind: EffectPoison, Strength: 1, Duration: 1}, {Kind: EffectPoison, Strength: 1, Duration: 1, Stacks: 10}},
+			instances: 1, stacks: 5, strength: 1, duration: 1,
+		},
+		{
+			name:      "independent keeps every application",
+			applied:   []Effect{{Kind: effectIndependent, Strength: 1, Duration: 1}, {Kind: effectIndependent, Strength: 2, Duration: 2}},
+			instances: 2, stacks: 1, strength: 1, duration: 1,
+		},
+		{
+			name:      "unknown kinds are ignored",
+			applied:   []Effect{{Kind: "nope", Duration: 1}},
+			instances: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnemy()
+			for _, fx := range tt.applied {
+				e.ApplyEffect(fx)
+			}
+
+			if len(e.Effects) != tt.instances {
+				t.Fatalf("got %d instances, want %d", len(e.Effects), tt.instances)
+			}
+			if tt.instances == 0 {
+				return
+			}
+			fx := e.Effects[0]
+			if fx.Stacks != tt.stacks || fx.Strength != tt.strength || fx.Duration != tt.duration {
+				t.Errorf("got stacks %d, strength %v, duration %v; want %d, %v, %v",
+					fx.Stacks, fx.Strength, fx.Duration, tt.stacks, tt.strength, tt.duration)
+			}
+		})
+	}
+}
+
+func TestApplyEffectOnDeadEnemy(t *testing.T) {
+	e := newTestEnemy()
+	e.Life = 0
+	e.ApplyEffect(Effect{Kind: EffectSlow, Strength: 0.5, Duration: 1})
+	if len(e.Effects) != 0 {
+		t.Errorf("dead enemy got %d effects", len(e.Effects))
+	}
+}
+
+func TestUpdateEffectsExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []float32
+		dt        float32
+		ticks     int
+		remaining int
+	}{
+		{name: "still active before the boundary", durations: []float32{0.5}, dt: 0.25, ticks: 1, remaining: 1},
+		{name: "expires exactly on the boundary", durations: []float32{0.5}, dt: 0.25, ticks: 2, remaining: 0},
+		{name: "expires part way through a tick", durations: []float32{0.375}, dt: 0.25, ticks: 2, remaining: 0},
+		{name: "independent instances expire on their own", durations: []float32{0.25, 0.75}, dt: 0.25, ticks: 1, remaining: 1},
+		{name: "the longer instance outlives the shorter", durations: []float32{0.25, 0.75}, dt: 0.25, ticks: 3, remaining: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnemy()
+			for _, duration := range tt.durations {
+				e.ApplyEffect(Effect{Kind: effectIndependent, Duration: duration})
+			}
+			for i := 0; i < tt.ticks; i++ {
+				e.UpdateEffects(tt.dt)
+			}
+			if len(e.Effects) != tt.remaining {
+				t.Errorf("got %d effects after %d ticks, want %d", len(e.Effects), tt.ticks, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestDamageOverTimeCarry(t *testing.T) {
+	tests := []struct {
+		name  string
+		fx    Effect
+		dt    float32
+		lives []int // Life after each tick
+	}{
+		{
+			// 0.25 damage per tick is carried until it adds up to 1
+			name:  "poison",
+			fx:    Effect{Kind: EffectPoison, Strength: 1, Duration: 1},
+			dt:    0.25,
+			lives: []int{100, 100, 100, 99},
+		},
+		{
+			name:  "poison scales with stacks",
+			fx:    Effect{Kind: EffectPoison, Strength: 1, Duration: 1, Stacks: 2},
+			dt:    0.25,
+			lives: []int{100, 99, 99, 98},
+		},
+		{
+			// 0.625 per tick: 0.625, 1.25, 0.875, 1.5
+			name:  "burn",
+			fx:    Effect{Kind: EffectBurn, Strength: 2.5, Duration: 1},
+			dt:    0.25,
+			lives: []int{100, 99, 99, 98},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnemy()
+			e.ApplyEffect(tt.fx)
+			for i, want := range tt.lives {
+				e.UpdateEffects(tt.dt)
+				if e.Life != want {
+					t.Fatalf("tick %d: life %d, want %d", i+1, e.Life, want)
+				}
+			}
+			if len(e.Effects) != 0 {
+				t.Errorf("effect still active after its duration")
+			}
+		})
+	}
+}
+
+func TestDamageOverTimeCreditsSource(t *testing.T) {
+	tower := &Tower{}
+	e := newTestEnemy()
+	e.Life = 1
+	e.ApplyEffect(Effect{Kind: EffectBurn, Strength: 4, Duration: 1, Source: tower})
+	e.UpdateEffects(0.25)
+
+	if e.IsAlive() {
+		t.Fatal("enemy survived the burn")
+	}
+	if tower.Kills != 1 {
+		t.Errorf("tower has %d kills, want 1", tower.Kills)
+	}
+}
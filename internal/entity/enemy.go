@@ -1,11 +1,7 @@
 package entity
 
 import (
-	"fmt"
-
-	"github.com/nx23/final-path/internal/config"
 	"github.com/nx23/final-path/internal/gamemap"
-	"github.com/nx23/final-path/internal/utils"
 )
 
 // Enemy is an enemy that follows the map path.
@@ -13,89 +9,178 @@ import (
 type Enemy struct {
 	PositionX        float32 // Center X
 	PositionY        float32 // Center Y
-	Speed            float32
-	CurrentPathIndex int
+	Kind             EnemyKind
+	Speed            float32 // Pixels per second
+	Lane             int     // Index into Map.Lanes
+	DistanceTraveled float32 // Distance walked along the lane, used to rank enemies by progress
 	Life             int
+	MaxLife          int
+	Armor            int
+	Bounty           int
+	LivesCost        int
+	Size             float32
+	Shield           int      // Absorbs damage before Life while the boss shield is up
+	Effects          []Effect // Active status effects
+	shieldUsed       bool
+	regenCarry       float32 // Fractional life regenerated but not yet applied
+	baseLife         int     // Wave base life before the archetype multiplier
+	baseSpeed        float32 // Wave base speed before the archetype multiplier
 }
 
+// NewEnemyParams describes an enemy to spawn. Speed and Life are the
+// wave's base values; the archetype's multipliers are applied on top.
 type NewEnemyParams struct {
 	Map   gamemap.Map
+	Lane  int
+	Kind  EnemyKind // Defaults to EnemyBasic
 	Speed float32
 	Life  int
 }
 
 func NewEnemy(params NewEnemyParams) *Enemy {
-	if len(params.Map) == 0 {
+	if params.Lane < 0 || params.Lane >= len(params.Map.Lanes) || len(params.Map.Lanes[params.Lane].Paths) == 0 {
 		return &Enemy{}
 	}
 
-	firstPath := params.Map[0]
+	kind := params.Kind
+	enemyType, ok := LookupEnemyType(kind)
+	if !ok {
+		kind = EnemyBasic
+		enemyType = EnemyTypes[EnemyBasic]
+	}
+
+	life := max(int(float32(params.Life)*enemyType.Health), 1)
+	x, y := params.Map.Lanes[params.Lane].PointAt(0)
 	return &Enemy{
-		PositionX:        utils.CenterInPath(firstPath.StartX, config.PathWidth),
-		PositionY:        utils.CenterInPath(firstPath.StartY, config.PathWidth),
-		Speed:            params.Speed,
-		CurrentPathIndex: 0,
-		Life:             params.Life,
+		PositionX:        x,
+		PositionY:        y,
+		Kind:             kind,
+		Speed:            params.Speed * enemyType.Speed,
+		Lane:             params.Lane,
+		DistanceTraveled: 0,
+		Life:             life,
+		MaxLife:          life,
+		Armor:            enemyType.Armor,
+		Bounty:           enemyType.Bounty,
+		LivesCost:        enemyType.LivesCost,
+		Size:             enemyType.Size,
+		baseLife:         params.Life,
+		baseSpeed:        params.Speed,
 	}
 }
 
+// Type returns the enemy's archetype
+func (e *Enemy) Type() EnemyType {
+	return EnemyTypes[e.Kind]
+}
+
 func (e *Enemy) IsAlive() bool {
 	return e.Life > 0
 }
 
-func (e *Enemy) TakeDamage(damage int) {
-	e.Life -= damage
-	if e.Life < 0 {
-		e.Life = 0
+// TakeDamage applies a hit resolved by ResolveDamage and returns the
+// damage dealt. Hits always deal at least 1.
+func (e *Enemy) TakeDamage(d Damage) int {
+	damage := max(int(e.ResolveDamage(d)), 1)
+	e.loseLife(damage, d.Source)
+	return damage
+}
+
+// loseLife removes already resolved damage and credits source with the
+// life it took and with the kill. An active shield soaks damage first;
+// dropping below the archetype's ShieldAt raises the shield once.
+func (e *Enemy) loseLife(damage int, source *Tower) {
+	wasAlive := e.IsAlive()
+
+	if e.Shield > 0 {
+		absorbed := min(damage, e.Shield)
+		e.Shield -= absorbed
+		damage -= absorbed
+	}
+
+	// Overkill beyond the life left is not credited
+	removed := min(damage, max(e.Life, 0))
+	e.Life -= removed
+	if source != nil {
+		source.DamageDealt += removed
+	}
+
+	if wasAlive && !e.IsAlive() && source != nil {
+		source.Kills++
+	}
+
+	enemyType := e.Type()
+	if !e.shieldUsed && enemyType.ShieldAt > 0 && e.IsAlive() &&
+		float32(e.Life) <= float32(e.MaxLife)*enemyType.ShieldAt {
+		e.shieldUsed = true
+		e.Shield = max(int(float32(e.MaxLife)*enemyType.ShieldLife), 1)
 	}
 }
 
-// FollowPath moves enemy along the map path
-func (e *Enemy) FollowPath(m gamemap.Map) {
-	if e.CurrentPathIndex >= len(m) {
+// Regenerate restores life for archetypes that heal over time.
+// Called once per tick of dt seconds.
+func (e *Enemy) Regenerate(dt float32) {
+	regen := e.Type().Regen
+	if regen <= 0 || !e.IsAlive() || e.Life >= e.MaxLife {
 		return
 	}
 
-	path := m[e.CurrentPathIndex]
-
-	// Vertical movement
-	if path.StartX == path.EndX {
-		// Center the enemy on X axis for this vertical path
-		targetCenterX := utils.CenterInPath(path.StartX, config.PathWidth)
-		e.PositionX = targetCenterX
-
-		targetCenterY := utils.CenterInPath(path.EndY, config.PathWidth)
-		if e.PositionY < targetCenterY {
-			e.PositionY += e.Speed
-		} else {
-			e.CurrentPathIndex++
-			fmt.Printf("Path %d completed\n", e.CurrentPathIndex)
-		}
-		// Horizontal movement
-	} else if path.StartY == path.EndY {
-		// Center the enemy on Y axis for this horizontal path
-		targetCenterY := utils.CenterInPath(path.StartY, config.PathWidth)
-		e.PositionY = targetCenterY
-
-		// Move right or left depending on direction
-		if path.EndX > path.StartX {
-			// Move right
-			targetCenterX := utils.CenterInPath(path.EndX, config.PathWidth)
-			if e.PositionX < targetCenterX {
-				e.PositionX += e.Speed
-			} else {
-				e.CurrentPathIndex++
-				fmt.Printf("Path %d completed\n", e.CurrentPathIndex)
-			}
-		} else {
-			// Move left
-			targetCenterX := utils.CenterInPath(path.EndX, config.PathWidth)
-			if e.PositionX > targetCenterX {
-				e.PositionX -= e.Speed
-			} else {
-				e.CurrentPathIndex++
-				fmt.Printf("Path %d completed\n", e.CurrentPathIndex)
-			}
-		}
+	e.regenCarry += float32(e.MaxLife) * regen * dt
+	healed := int(e.regenCarry)
+	e.regenCarry -= float32(healed)
+	e.Life = min(e.Life+healed, e.MaxLife)
+}
+
+// Split returns the enemies spawned when this one dies, placed just
+// behind it on the same lane
+func (e *Enemy) Split(m gamemap.Map) []*Enemy {
+	enemyType := e.Type()
+	if enemyType.SplitInto == "" || enemyType.SplitCount <= 0 {
+		return nil
+	}
+
+	children := make([]*Enemy, 0, enemyType.SplitCount)
+	for i := 0; i < enemyType.SplitCount; i++ {
+		child := NewEnemy(NewEnemyParams{
+			Map:   m,
+			Lane:  e.Lane,
+			Kind:  enemyType.SplitInto,
+			Speed: e.baseSpeed,
+			Life:  e.baseLife,
+		})
+		child.DistanceTraveled = max(e.DistanceTraveled-float32(i)*child.Size, 0)
+		child.PositionX, child.PositionY = m.Lanes[e.Lane].PointAt(child.DistanceTraveled)
+		children = append(children, child)
 	}
+
+	return children
+}
+
+// HasEscaped reports whether the enemy walked past the end of its lane
+func (e *Enemy) HasEscaped(m gamemap.Map) bool {
+	return e.DistanceTraveled >= m.Lanes[e.Lane].Length()
+}
+
+// Remaining returns the distance left before the enemy escapes
+func (e *Enemy) Remaining(m gamemap.Map) float32 {
+	return m.Lanes[e.Lane].Length() - e.DistanceTraveled
+}
+
+// PredictPosition returns where the enemy will be after the given seconds
+// if its speed does not change
+func (e *Enemy) PredictPosition(m gamemap.Map, seconds float32) (float32, float32) {
+	return m.Lanes[e.Lane].PointAt(e.DistanceTraveled + e.Speed*e.SpeedMultiplier()*seconds)
+}
+
+// FollowPath moves enemy along its lane of the map for dt seconds.
+// Movement is tracked as distance along the lane, so leftover movement
+// carries over corners exactly at any speed. Status effects such as slow
+// and stun scale the distance covered.
+func (e *Enemy) FollowPath(m gamemap.Map, dt float32) {
+	if e.HasEscaped(m) {
+		return
+	}
+
+	e.DistanceTraveled += e.Speed * e.SpeedMultiplier() * dt
+	e.PositionX, e.PositionY = m.Lanes[e.Lane].PointAt(e.DistanceTraveled)
 }
@@ -0,0 +1,37 @@
+package entity
+
+import "testing"
+
+func TestLoseLifeCreditsRemovedLife(t *testing.T) {
+	tests := []struct {
+		name       string
+		life       int
+		shield     int
+		damage     int
+		dealt      int
+		wantLife   int
+		wantShield int
+	}{
+		{name: "plain hit", life: 100, damage: 30, dealt: 30, wantLife: 70},
+		{name: "overkill", life: 10, damage: 30, dealt: 10, wantLife: 0},
+		{name: "shield soaks it all", life: 100, shield: 40, damage: 30, dealt: 0, wantLife: 100, wantShield: 10},
+		{name: "through the shield", life: 100, shield: 10, damage: 30, dealt: 20, wantLife: 80},
+		{name: "through the shield and overkill", life: 5, shield: 10, damage: 30, dealt: 5, wantLife: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tower := &Tower{}
+			e := newTestEnemy()
+			e.Life, e.Shield = tt.life, tt.shield
+			e.loseLife(tt.damage, tower)
+
+			if tower.DamageDealt != tt.dealt {
+				t.Errorf("tower credited %d damage, want %d", tower.DamageDealt, tt.dealt)
+			}
+			if e.Life != tt.wantLife || e.Shield != tt.wantShield {
+				t.Errorf("got life %d, shield %d; want %d, %d", e.Life, e.Shield, tt.wantLife, tt.wantShield)
+			}
+		})
+	}
+}
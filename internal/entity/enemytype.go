@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"image/color"
+	"sort"
+)
+
+// EnemyKind identifies an enemy archetype in the EnemyTypes registry
+type EnemyKind string
+
+const (
+	EnemyBasic        EnemyKind = "basic"
+	EnemyArmored      EnemyKind = "armored"
+	EnemyFast         EnemyKind = "fast"
+	EnemySwarm        EnemyKind = "swarm"
+	EnemySwarmling    EnemyKind = "swarmling"
+	EnemyRegenerating EnemyKind = "regenerating"
+	EnemyBoss         EnemyKind = "boss"
+)
+
+// EnemyShape is how the renderer draws an archetype
+type EnemyShape int
+
+const (
+	ShapeSquare EnemyShape = iota
+	ShapeCircle
+	ShapeDiamond
+)
+
+// EnemyType holds the stats and behaviours shared by every enemy of a kind.
+// Health and Speed multiply the wave's base values so archetypes keep
+// scaling with the wave number.
+type EnemyType struct {
+	Name      string
+	Health    float32 // Multiplier on the wave's base life
+	Armor     int     // Flat damage removed from every physical hit (hits always deal at least 1)
+	Speed     float32 // Multiplier on the wave's base speed
+	Bounty    int     // Coins awarded when killed
+	LivesCost int     // Lives lost when it escapes
+	Size      float32
+	Color     color.RGBA
+	Shape     EnemyShape
+
+	// Resistances is the fraction of each damage type ignored; negative
+	// values are weaknesses. Types not listed take full damage.
+	Resistances map[DamageType]float32
+
+	// Behaviours (zero values disable them)
+	Regen      float32   // Fraction of max life restored per second
+	SplitInto  EnemyKind // Kind spawned when this enemy dies
+	SplitCount int       // How many SplitInto enemies are spawned
+	ShieldAt   float32   // Fraction of max life that triggers the shield phase
+	ShieldLife float32   // Shield strength as a fraction of max life
+}
+
+// EnemyTypes is the registry of every enemy archetype
+var EnemyTypes = map[EnemyKind]EnemyType{
+	EnemyBasic: {
+		Name:      "Grunt",
+		Health:    1,
+		Speed:     1,
+		Bounty:    5,
+		LivesCost: 1,
+		Size:      25,
+		Color:     color.RGBA{255, 0, 0, 255},
+		Shape:     ShapeSquare,
+	},
+	EnemyArmored: {
+		Name:      "Armored",
+		Health:    1.5,
+		Armor:     4,
+		Speed:     0.7,
+		Bounty:    8,
+		LivesCost: 1,
+		Size:      27,
+		Color:     color.RGBA{140, 140, 160, 255},
+		Shape:     ShapeSquare,
+		Resistances: map[DamageType]float32{
+			DamageFire:  0.25,
+			DamageMagic: -0.25,
+		},
+	},
+	EnemyFast: {
+		Name:      "Runner",
+		Health:    0.6,
+		Speed:     1.8,
+		Bounty:    6,
+		LivesCost: 1,
+		Size:      22,
+		Color:     color.RGBA{255, 220, 0, 255},
+		Shape:     ShapeDiamond,
+	},
+	EnemySwarm: {
+		Name:       "Swarm",
+		Health:     1,
+		Speed:      0.9,
+		Bounty:     4,
+		LivesCost:  1,
+		Size:       24,
+		Color:      color.RGBA{255, 120, 0, 255},
+		Shape:      ShapeCircle,
+		SplitInto:  EnemySwarmling,
+		SplitCount: 3,
+	},
+	EnemySwarmling: {
+		Name:      "Swarmling",
+		Health:    0.25,
+		Speed:     1.3,
+		Bounty:    1,
+		LivesCost: 1,
+		Size:      12,
+		Color:     color.RGBA{255, 170, 60, 255},
+		Shape:     ShapeCircle,
+	},
+	EnemyRegenerating: {
+		Name:      "Troll",
+		Health:    1.3,
+		Speed:     0.9,
+		Bounty:    7,
+		LivesCost: 1,
+		Size:      26,
+		Color:     color.RGBA{0, 200, 80, 255},
+		Shape:     ShapeCircle,
+		Regen:     0.05,
+		Resistances: map[DamageType]float32{
+			DamageIce:  0.5,
+			DamageFire: -0.5,
+		},
+	},
+	EnemyBoss: {
+		Name:       "Warlord",
+		Health:     10,
+		Armor:      2,
+		Speed:      0.5,
+		Bounty:     50,
+		LivesCost:  5,
+		Size:       40,
+		Color:      color.RGBA{170, 0, 220, 255},
+		Shape:      ShapeSquare,
+		ShieldAt:   0.5,
+		ShieldLife: 0.3,
+		Resistances: map[DamageType]float32{
+			DamageMagic: 0.3,
+			DamageIce:   0.5,
+		},
+	},
+}
+
+// LookupEnemyType returns the registered archetype for kind
+func LookupEnemyType(kind EnemyKind) (EnemyType, bool) {
+	enemyType, ok := EnemyTypes[kind]
+	return enemyType, ok
+}
+
+// largestEnemySize returns the size of the biggest registered archetype
+func largestEnemySize() float32 {
+	var largest float32
+	for _, enemyType := range EnemyTypes {
+		largest = max(largest, enemyType.Size)
+	}
+	return largest
+}
+
+// EnemyKindNames returns the name of every registered kind, sorted
+func EnemyKindNames() []string {
+	names := make([]string, 0, len(EnemyTypes))
+	for kind := range EnemyTypes {
+		names = append(names, string(kind))
+	}
+	sort.Strings(names)
+	return names
+}
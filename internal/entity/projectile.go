@@ -1,43 +1,260 @@
 package entity
 
-import "math"
+import (
+	"math"
 
-// Projectile chases enemies until hitting them
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/spatial"
+	"github.com/nx23/final-path/internal/utils"
+)
+
+// ProjectileKind decides how a projectile moves and what it can hit
+type ProjectileKind int
+
+const (
+	// ProjectileHoming chases its target and retargets the nearest enemy
+	// when the target dies first
+	ProjectileHoming ProjectileKind = iota
+	// ProjectileBallistic flies straight at where its target is predicted
+	// to be and hits whatever it touches on the way. Shells (splash) burst
+	// on the first enemy or at the aim point; bolts pierce through enemies.
+	ProjectileBallistic
+)
+
+// retargetRange is how far a homing projectile looks for a new target
+const retargetRange float32 = 150
+
+// Projectile is a shot fired by a tower
 type Projectile struct {
-	PositionX float32 // Center X
-	PositionY float32 // Center Y
-	Speed     int
-	Target    *Enemy
+	PositionX   float32 // Center X
+	PositionY   float32 // Center Y
+	Speed       float32 // Pixels per second
+	Target      *Enemy
+	Kind        ProjectileKind
+	Tower       TowerKind // Type of the tower that fired it
+	Damage      int
+	Source      *Tower   // Credited with the damage and kills
+	Effects     []Effect // Applied to every enemy it damages
+	DamageType  DamageType
+	Critical    bool
+	Pierce      int     // Ballistic only: enemies it can still pass through
+	DirectionX  float32 // Ballistic only: unit direction of travel
+	DirectionY  float32
+	MaxDistance float32 // Ballistic only: distance flown before it is spent
+	traveled    float32
+	hit         map[*Enemy]bool // Enemies a piercing shot already went through
 }
 
 func NewProjectile(x, y float32, target *Enemy) Projectile {
 	return Projectile{
-		PositionX: x,
-		PositionY: y,
-		Speed:     10,
-		Target:    target,
+		PositionX:  x,
+		PositionY:  y,
+		Speed:      600,
+		Target:     target,
+		Kind:       ProjectileHoming,
+		Tower:      TowerGun,
+		DamageType: DamagePhysical,
+	}
+}
+
+// Type returns the type of the tower that fired the projectile
+func (p *Projectile) Type() TowerType {
+	return TowerTypes[p.Tower]
+}
+
+// Aim points a ballistic projectile at where the target will be when the
+// shot arrives and returns that point
+func (p *Projectile) Aim(target *Enemy, m gamemap.Map) (float32, float32) {
+	aimX, aimY := target.PositionX, target.PositionY
+
+	// Refine the flight time twice; enough for the speeds involved
+	for i := 0; i < 2; i++ {
+		seconds := distance(p.PositionX, p.PositionY, aimX, aimY) / p.Speed
+		aimX, aimY = target.PredictPosition(m, seconds)
+	}
+
+	if d := distance(p.PositionX, p.PositionY, aimX, aimY); d > 0 {
+		p.DirectionX = (aimX - p.PositionX) / d
+		p.DirectionY = (aimY - p.PositionY) / d
 	}
+	return aimX, aimY
 }
 
-// Hit returns true when projectile reaches the target
-func (p *Projectile) Hit() bool {
+// Update moves the projectile for a tick of dt seconds and resolves its
+// hits with damage. enemies indexes every enemy by position and is used
+// for collisions, splash, chaining and retargeting. It returns how many
+// enemies were damaged and whether the projectile is still in flight.
+func (p *Projectile) Update(enemies *spatial.Grid[*Enemy], damage int, dt float32) (hits int, active bool) {
+	if p.Kind == ProjectileBallistic {
+		return p.updateBallistic(enemies, damage, dt)
+	}
+	return p.updateHoming(enemies, damage, dt)
+}
+
+func (p *Projectile) updateHoming(enemies *spatial.Grid[*Enemy], damage int, dt float32) (int, bool) {
 	if p.Target == nil || !p.Target.IsAlive() {
-		return false
+		nearby := enemies.Query(nil, p.PositionX, p.PositionY, retargetRange)
+		p.Target = nearestEnemy(nearby, p.PositionX, p.PositionY, retargetRange)
+		if p.Target == nil {
+			return 0, false
+		}
 	}
 
 	// Calculate direction and move in a straight line
 	dx := p.Target.PositionX - p.PositionX
 	dy := p.Target.PositionY - p.PositionY
-	distance := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+	d := distance(p.PositionX, p.PositionY, p.Target.PositionX, p.Target.PositionY)
+	step := p.Speed * dt
 
-	if distance < float32(p.Speed) {
+	if d < step {
 		// Reached the target
-		return true
+		return p.hitEnemy(p.Target, enemies, damage), false
 	}
 
 	// Move towards the target
-	p.PositionX += (dx / distance) * float32(p.Speed)
-	p.PositionY += (dy / distance) * float32(p.Speed)
+	p.PositionX += (dx / d) * step
+	p.PositionY += (dy / d) * step
+
+	return 0, true
+}
+
+func (p *Projectile) updateBallistic(enemies *spatial.Grid[*Enemy], damage int, dt float32) (int, bool) {
+	step := min(p.Speed*dt, p.MaxDistance-p.traveled)
+	startX, startY := p.PositionX, p.PositionY
+	p.PositionX += p.DirectionX * step
+	p.PositionY += p.DirectionY * step
+	p.traveled += step
+
+	// Hit every enemy touched along this tick's movement. Candidates are
+	// searched around the middle of the movement, far enough to reach the
+	// edge of the largest enemy at either end.
+	searchRadius := step/2 + largestEnemySize()/2 + p.Type().ProjectileSize
+	nearby := enemies.Query(nil, (startX+p.PositionX)/2, (startY+p.PositionY)/2, searchRadius)
+	hits := 0
+	for _, enemy := range nearby {
+		if !enemy.IsAlive() || p.hit[enemy] {
+			continue
+		}
+		radius := enemy.Size/2 + p.Type().ProjectileSize
+		if utils.DistanceToSegment(enemy.PositionX, enemy.PositionY, startX, startY, p.PositionX, p.PositionY) > radius {
+			continue
+		}
+
+		hits += p.hitEnemy(enemy, enemies, damage)
+		if p.Pierce <= 0 {
+			return hits, false
+		}
+		p.Pierce--
+		if p.hit == nil {
+			p.hit = map[*Enemy]bool{}
+		}
+		p.hit[enemy] = true
+	}
+
+	if p.traveled >= p.MaxDistance {
+		// Shells that missed burst where they were aimed
+		if radius := p.Type().SplashRadius; radius > 0 {
+			hits += p.splash(p.PositionX, p.PositionY, radius, enemies, damage, nil)
+		}
+		return hits, false
+	}
+
+	return hits, true
+}
+
+// hitEnemy deals damage to target and applies the tower type's area
+// behaviours: splash hits every enemy around the target and lightning
+// jumps to nearby enemies with decreasing damage. It returns how many
+// enemies were damaged.
+func (p *Projectile) hitEnemy(target *Enemy, enemies *spatial.Grid[*Enemy], damage int) int {
+	towerType := p.Type()
+
+	p.damage(target, damage)
+	hits := 1
+
+	if towerType.SplashRadius > 0 {
+		hits += p.splash(target.PositionX, target.PositionY, towerType.SplashRadius, enemies, damage, target)
+	}
+
+	// Each jump goes to the closest enemy not hit yet
+	chained := map[*Enemy]bool{target: true}
+	current := target
+	chainDamage := float32(damage)
+	for i := 0; i < towerType.ChainCount; i++ {
+		var next *Enemy
+		best := towerType.ChainRange * towerType.ChainRange
+		for _, enemy := range enemies.Query(nil, current.PositionX, current.PositionY, towerType.ChainRange) {
+			if chained[enemy] || !enemy.IsAlive() {
+				continue
+			}
+			if d := distanceSquared(enemy, current); d <= best {
+				next, best = enemy, d
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		chainDamage *= towerType.ChainFalloff
+		p.damage(next, max(int(chainDamage), 1))
+		chained[next] = true
+		current = next
+		hits++
+	}
+
+	return hits
+}
+
+// splash damages every living enemy within radius of (x, y) except skip
+func (p *Projectile) splash(x, y, radius float32, enemies *spatial.Grid[*Enemy], damage int, skip *Enemy) int {
+	hits := 0
+	for _, enemy := range enemies.Query(nil, x, y, radius) {
+		if enemy != skip && enemy.IsAlive() {
+			p.damage(enemy, damage)
+			hits++
+		}
+	}
+	return hits
+}
+
+// damage hits an enemy with a packet from the source tower and applies
+// the projectile's effects
+func (p *Projectile) damage(enemy *Enemy, amount int) {
+	enemy.TakeDamage(Damage{
+		Amount:   float32(amount),
+		Type:     p.DamageType,
+		Critical: p.Critical,
+		Source:   p.Source,
+	})
+	for _, fx := range p.Effects {
+		fx.Source = p.Source
+		enemy.ApplyEffect(fx)
+	}
+}
+
+// nearestEnemy returns the living enemy closest to (x, y) within maxRange
+func nearestEnemy(enemies []*Enemy, x, y, maxRange float32) *Enemy {
+	var nearest *Enemy
+	best := maxRange
+	for _, enemy := range enemies {
+		if !enemy.IsAlive() {
+			continue
+		}
+		if d := distance(x, y, enemy.PositionX, enemy.PositionY); d <= best {
+			nearest, best = enemy, d
+		}
+	}
+	return nearest
+}
+
+func distance(ax, ay, bx, by float32) float32 {
+	dx := bx - ax
+	dy := by - ay
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
+}
 
-	return false
+func distanceSquared(a, b *Enemy) float32 {
+	dx := a.PositionX - b.PositionX
+	dy := a.PositionY - b.PositionY
+	return dx*dx + dy*dy
 }
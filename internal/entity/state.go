@@ -0,0 +1,304 @@
+package entity
+
+import "slices"
+
+// The State types are the saved form of entities. Pointers between
+// entities (effect and projectile sources, projectile targets) are saved
+// as indexes into the match's tower and enemy lists, -1 for none.
+
+// TowerState is the saved form of a tower
+type TowerState struct {
+	X            float32    `json:"x"`
+	Y            float32    `json:"y"`
+	Kind         TowerKind  `json:"kind"`
+	Range        float32    `json:"range"`
+	Damage       int        `json:"damage"`
+	FireRate     float32    `json:"fireRate"`
+	LastFireTime float64    `json:"lastFireTime"`
+	Level        int        `json:"level"`
+	Invested     int        `json:"invested"`
+	Kills        int        `json:"kills"`
+	DamageDealt  int        `json:"damageDealt"`
+	Targeting    TargetMode `json:"targeting"`
+	Shots        int        `json:"shots"`
+}
+
+// EffectState is the saved form of a status effect
+type EffectState struct {
+	Kind     EffectKind `json:"kind"`
+	Strength float32    `json:"strength"`
+	Duration float32    `json:"duration"`
+	Stacks   int        `json:"stacks"`
+	Source   int        `json:"source"`
+	Carry    float32    `json:"carry"`
+}
+
+// EnemyState is the saved form of an enemy
+type EnemyState struct {
+	X                float32       `json:"x"`
+	Y                float32       `json:"y"`
+	Kind             EnemyKind     `json:"kind"`
+	Speed            float32       `json:"speed"`
+	Lane             int           `json:"lane"`
+	DistanceTraveled float32       `json:"distanceTraveled"`
+	Life             int           `json:"life"`
+	MaxLife          int           `json:"maxLife"`
+	Armor            int           `json:"armor"`
+	Bounty           int           `json:"bounty"`
+	LivesCost        int           `json:"livesCost"`
+	Size             float32       `json:"size"`
+	Shield           int           `json:"shield"`
+	ShieldUsed       bool          `json:"shieldUsed"`
+	RegenCarry       float32       `json:"regenCarry"`
+	BaseLife         int           `json:"baseLife"`
+	BaseSpeed        float32       `json:"baseSpeed"`
+	Effects          []EffectState `json:"effects,omitempty"`
+}
+
+// ProjectileState is the saved form of a projectile in flight
+type ProjectileState struct {
+	X           float32        `json:"x"`
+	Y           float32        `json:"y"`
+	Speed       float32        `json:"speed"`
+	Target      int            `json:"target"`
+	Kind        ProjectileKind `json:"kind"`
+	Tower       TowerKind      `json:"tower"`
+	Damage      int            `json:"damage"`
+	Source      int            `json:"source"`
+	Effects     []EffectState  `json:"effects,omitempty"`
+	DamageType  DamageType     `json:"damageType"`
+	Critical    bool           `json:"critical"`
+	Pierce      int            `json:"pierce"`
+	DirectionX  float32        `json:"directionX"`
+	DirectionY  float32        `json:"directionY"`
+	MaxDistance float32        `json:"maxDistance"`
+	Traveled    float32        `json:"traveled"`
+	Hit         []int          `json:"hit,omitempty"`
+}
+
+// Index numbers the towers and enemies of a match for saving
+type Index struct {
+	towers  map[*Tower]int
+	enemies map[*Enemy]int
+}
+
+// NewIndex numbers towers and enemies by their position in the slices
+func NewIndex(towers []*Tower, enemies []*Enemy) Index {
+	index := Index{
+		towers:  make(map[*Tower]int, len(towers)),
+		enemies: make(map[*Enemy]int, len(enemies)),
+	}
+	for i, tower := range towers {
+		index.towers[tower] = i
+	}
+	for i, enemy := range enemies {
+		index.enemies[enemy] = i
+	}
+	return index
+}
+
+// Tower returns the number of a tower, -1 for nil or a tower not indexed
+func (ix Index) Tower(t *Tower) int {
+	if i, ok := ix.towers[t]; ok {
+		return i
+	}
+	return -1
+}
+
+// Enemy returns the number of an enemy, -1 for nil or an enemy not indexed
+func (ix Index) Enemy(e *Enemy) int {
+	if i, ok := ix.enemies[e]; ok {
+		return i
+	}
+	return -1
+}
+
+// at returns items[i], or nil when i is out of range (-1 included)
+func at[T any](items []*T, i int) *T {
+	if i < 0 || i >= len(items) {
+		return nil
+	}
+	return items[i]
+}
+
+// State returns the saved form of the tower
+func (t *Tower) State() TowerState {
+	return TowerState{
+		X:            t.PositionX,
+		Y:            t.PositionY,
+		Kind:         t.Kind,
+		Range:        t.Range,
+		Damage:       t.Damage,
+		FireRate:     t.FireRate,
+		LastFireTime: t.LastFireTime,
+		Level:        t.Level,
+		Invested:     t.Invested,
+		Kills:        t.Kills,
+		DamageDealt:  t.DamageDealt,
+		Targeting:    t.Targeting,
+		Shots:        t.shots,
+	}
+}
+
+// Restore rebuilds the saved tower
+func (s TowerState) Restore() *Tower {
+	return &Tower{
+		PositionX:    s.X,
+		PositionY:    s.Y,
+		Kind:         s.Kind,
+		Range:        s.Range,
+		Damage:       s.Damage,
+		FireRate:     s.FireRate,
+		LastFireTime: s.LastFireTime,
+		Level:        s.Level,
+		Invested:     s.Invested,
+		Kills:        s.Kills,
+		DamageDealt:  s.DamageDealt,
+		Targeting:    s.Targeting,
+		shots:        s.Shots,
+	}
+}
+
+func effectStates(effects []Effect, ix Index) []EffectState {
+	var states []EffectState
+	for _, fx := range effects {
+		states = append(states, EffectState{
+			Kind:     fx.Kind,
+			Strength: fx.Strength,
+			Duration: fx.Duration,
+			Stacks:   fx.Stacks,
+			Source:   ix.Tower(fx.Source),
+			Carry:    fx.carry,
+		})
+	}
+	return states
+}
+
+func restoreEffects(states []EffectState, towers []*Tower) []Effect {
+	var effects []Effect
+	for _, s := range states {
+		effects = append(effects, Effect{
+			Kind:     s.Kind,
+			Strength: s.Strength,
+			Duration: s.Duration,
+			Stacks:   s.Stacks,
+			Source:   at(towers, s.Source),
+			carry:    s.Carry,
+		})
+	}
+	return effects
+}
+
+// State returns the saved form of the enemy
+func (e *Enemy) State(ix Index) EnemyState {
+	return EnemyState{
+		X:                e.PositionX,
+		Y:                e.PositionY,
+		Kind:             e.Kind,
+		Speed:            e.Speed,
+		Lane:             e.Lane,
+		DistanceTraveled: e.DistanceTraveled,
+		Life:             e.Life,
+		MaxLife:          e.MaxLife,
+		Armor:            e.Armor,
+		Bounty:           e.Bounty,
+		LivesCost:        e.LivesCost,
+		Size:             e.Size,
+		Shield:           e.Shield,
+		ShieldUsed:       e.shieldUsed,
+		RegenCarry:       e.regenCarry,
+		BaseLife:         e.baseLife,
+		BaseSpeed:        e.baseSpeed,
+		Effects:          effectStates(e.Effects, ix),
+	}
+}
+
+// Restore rebuilds the saved enemy. towers are the restored towers the
+// effect sources refer to.
+func (s EnemyState) Restore(towers []*Tower) *Enemy {
+	return &Enemy{
+		PositionX:        s.X,
+		PositionY:        s.Y,
+		Kind:             s.Kind,
+		Speed:            s.Speed,
+		Lane:             s.Lane,
+		DistanceTraveled: s.DistanceTraveled,
+		Life:             s.Life,
+		MaxLife:          s.MaxLife,
+		Armor:            s.Armor,
+		Bounty:           s.Bounty,
+		LivesCost:        s.LivesCost,
+		Size:             s.Size,
+		Shield:           s.Shield,
+		Effects:          restoreEffects(s.Effects, towers),
+		shieldUsed:       s.ShieldUsed,
+		regenCarry:       s.RegenCarry,
+		baseLife:         s.BaseLife,
+		baseSpeed:        s.BaseSpeed,
+	}
+}
+
+// State returns the saved form of the projectile
+func (p *Projectile) State(ix Index) ProjectileState {
+	var hit []int
+	for enemy := range p.hit {
+		if i := ix.Enemy(enemy); i >= 0 {
+			hit = append(hit, i)
+		}
+	}
+	slices.Sort(hit)
+
+	return ProjectileState{
+		X:           p.PositionX,
+		Y:           p.PositionY,
+		Speed:       p.Speed,
+		Target:      ix.Enemy(p.Target),
+		Kind:        p.Kind,
+		Tower:       p.Tower,
+		Damage:      p.Damage,
+		Source:      ix.Tower(p.Source),
+		Effects:     effectStates(p.Effects, ix),
+		DamageType:  p.DamageType,
+		Critical:    p.Critical,
+		Pierce:      p.Pierce,
+		DirectionX:  p.DirectionX,
+		DirectionY:  p.DirectionY,
+		MaxDistance: p.MaxDistance,
+		Traveled:    p.traveled,
+		Hit:         hit,
+	}
+}
+
+// Restore rebuilds the saved projectile. towers and enemies are the
+// restored entities its source, target and pierced enemies refer to.
+func (s ProjectileState) Restore(towers []*Tower, enemies []*Enemy) Projectile {
+	var hit map[*Enemy]bool
+	for _, i := range s.Hit {
+		if enemy := at(enemies, i); enemy != nil {
+			if hit == nil {
+				hit = map[*Enemy]bool{}
+			}
+			hit[enemy] = true
+		}
+	}
+
+	return Projectile{
+		PositionX:   s.X,
+		PositionY:   s.Y,
+		Speed:       s.Speed,
+		Target:      at(enemies, s.Target),
+		Kind:        s.Kind,
+		Tower:       s.Tower,
+		Damage:      s.Damage,
+		Source:      at(towers, s.Source),
+		Effects:     restoreEffects(s.Effects, towers),
+		DamageType:  s.DamageType,
+		Critical:    s.Critical,
+		Pierce:      s.Pierce,
+		DirectionX:  s.DirectionX,
+		DirectionY:  s.DirectionY,
+		MaxDistance: s.MaxDistance,
+		traveled:    s.Traveled,
+		hit:         hit,
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import "github.com/nx23/final-path/internal/gamemap"
+
+// TargetMode decides which enemy in range a tower shoots at
+type TargetMode int
+
+const (
+	TargetFirst     TargetMode = iota // Closest to escaping
+	TargetLast                        // Furthest from escaping
+	TargetStrongest                   // Most life left
+	TargetWeakest                     // Least life left
+	TargetClosest                     // Nearest to the tower
+)
+
+// TargetModes lists every mode in the order the info panel cycles them
+var TargetModes = []TargetMode{TargetFirst, TargetLast, TargetStrongest, TargetWeakest, TargetClosest}
+
+func (m TargetMode) String() string {
+	switch m {
+	case TargetFirst:
+		return "First"
+	case TargetLast:
+		return "Last"
+	case TargetStrongest:
+		return "Strongest"
+	case TargetWeakest:
+		return "Weakest"
+	case TargetClosest:
+		return "Closest"
+	default:
+		return "Unknown"
+	}
+}
+
+// Next returns the mode after m, wrapping around
+func (m TargetMode) Next() TargetMode {
+	return TargetModes[(int(m)+1)%len(TargetModes)]
+}
+
+// SelectTarget returns the living enemy in range that best matches the
+// tower's targeting mode, or nil. enemies may be every enemy or only the
+// candidates near the tower. Ties go to the enemy listed first.
+func (t *Tower) SelectTarget(enemies []*Enemy, m gamemap.Map) *Enemy {
+	var best *Enemy
+	var bestScore float32
+
+	for _, enemy := range enemies {
+		if !enemy.IsAlive() || !t.IsEnemyInRange(enemy) {
+			continue
+		}
+
+		// Higher scores win
+		var score float32
+		switch t.Targeting {
+		case TargetFirst:
+			score = -enemy.Remaining(m)
+		case TargetLast:
+			score = enemy.Remaining(m)
+		case TargetStrongest:
+			score = float32(enemy.Life)
+		case TargetWeakest:
+			score = -float32(enemy.Life)
+		case TargetClosest:
+			dx := t.PositionX - enemy.PositionX
+			dy := t.PositionY - enemy.PositionY
+			score = -(dx*dx + dy*dy)
+		}
+
+		if best == nil || score > bestScore {
+			best, bestScore = enemy, score
+		}
+	}
+
+	return best
+}
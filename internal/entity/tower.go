@@ -10,21 +10,80 @@ import (
 type Tower struct {
 	PositionX    float32 // Center X
 	PositionY    float32 // Center Y
+	Kind         TowerKind
 	Range        float32
 	Damage       int
 	FireRate     float32
-	LastFireTime int
+	LastFireTime float64 // Simulation time of the last shot in seconds
+	Level        int     // Upgrade tiers bought
+	Invested     int     // Coins spent placing and upgrading, the basis of the sell value
+	Kills        int
+	DamageDealt  int // Enemy life removed, not counting shields and overkill
+	Targeting    TargetMode
+	shots        int // Shots fired, used to time critical hits
 }
 
-func NewTower(x, y float32) Tower {
-	return Tower{
-		PositionX:    x,
-		PositionY:    y,
-		Range:        100,
-		Damage:       10,
-		FireRate:     1,
-		LastFireTime: -60, // Start with cooldown ready (1 second ago)
+// NewTower creates a tower of the given kind with its type's base stats.
+// Unknown kinds fall back to the gun. cost is what the player paid.
+func NewTower(x, y float32, kind TowerKind, cost int) *Tower {
+	towerType, ok := LookupTowerType(kind)
+	if !ok {
+		kind = TowerGun
+		towerType = TowerTypes[TowerGun]
 	}
+
+	return &Tower{
+		PositionX: x,
+		PositionY: y,
+		Kind:      kind,
+		Range:     towerType.Range,
+		Damage:    towerType.Damage,
+		FireRate:  towerType.FireRate,
+		Invested:  cost,
+	}
+}
+
+// Type returns the tower's type
+func (t *Tower) Type() TowerType {
+	return TowerTypes[t.Kind]
+}
+
+// NextUpgrade returns the next tier of the tower's upgrade path, or
+// false once every tier has been bought
+func (t *Tower) NextUpgrade() (TowerUpgrade, bool) {
+	upgrades := t.Type().Upgrades
+	if t.Level >= len(upgrades) {
+		return TowerUpgrade{}, false
+	}
+	return upgrades[t.Level], true
+}
+
+// Upgrade applies the next tier's bonuses. The caller pays for it.
+func (t *Tower) Upgrade() bool {
+	upgrade, ok := t.NextUpgrade()
+	if !ok {
+		return false
+	}
+
+	t.Level++
+	t.Invested += upgrade.Cost
+	t.Damage += upgrade.Damage
+	t.Range += upgrade.Range
+	t.FireRate += upgrade.FireRate
+	return true
+}
+
+// SellValue returns the coins refunded for the tower: rate of everything
+// invested in it
+func (t *Tower) SellValue(rate float32) int {
+	return int(float32(t.Invested) * rate)
+}
+
+// Contains reports whether (x, y) is over the tower
+func (t *Tower) Contains(x, y float32) bool {
+	const halfSize = config.TowerSize / 2
+	return x >= t.PositionX-halfSize && x <= t.PositionX+halfSize &&
+		y >= t.PositionY-halfSize && y <= t.PositionY+halfSize
 }
 
 // IsEnemyInRange uses squared distance to avoid sqrt
@@ -35,16 +94,50 @@ func (t *Tower) IsEnemyInRange(enemy *Enemy) bool {
 	return distanceSquared <= t.Range*t.Range
 }
 
-func (t *Tower) CanFire(currentTick int) bool {
-	ticksPerShot := int(60.0 / t.FireRate)
-	return currentTick-t.LastFireTime >= ticksPerShot
+// CanFire reports whether the tower has reloaded at simulation time now,
+// in seconds. boost multiplies the fire rate. A tower that never fired is
+// always ready. A microsecond of slack keeps rounding in the clock from
+// delaying a shot by a whole tick.
+func (t *Tower) CanFire(now float64, boost float32) bool {
+	if t.shots == 0 {
+		return true
+	}
+	return now-t.LastFireTime >= 1/float64(t.FireRate*boost)-1e-6
 }
 
-func (t *Tower) Attack(enemy *Enemy) Projectile {
-	return NewProjectile(t.PositionX, t.PositionY, enemy)
+// Attack fires a projectile carrying the tower's damage and behaviour.
+// Ballistic shots are aimed at where the enemy will be on m: shells fly
+// to that point, bolts keep going up to one and a half times the range.
+func (t *Tower) Attack(enemy *Enemy, m gamemap.Map) Projectile {
+	towerType := t.Type()
+	projectile := NewProjectile(t.PositionX, t.PositionY, enemy)
+	projectile.Kind = towerType.Projectile
+	projectile.Tower = t.Kind
+	projectile.Source = t
+	projectile.Damage = t.Damage
+	projectile.DamageType = towerType.DamageType
+	projectile.Speed = towerType.ProjectileSpeed
+	projectile.Effects = towerType.Effects
+	projectile.Pierce = towerType.Pierce
+
+	if projectile.Kind == ProjectileBallistic {
+		aimX, aimY := projectile.Aim(enemy, m)
+		if towerType.SplashRadius > 0 {
+			projectile.MaxDistance = distance(t.PositionX, t.PositionY, aimX, aimY)
+		} else {
+			projectile.MaxDistance = t.Range * 1.5
+		}
+	}
+
+	t.shots++
+	if every := towerType.CritEvery; every > 0 && t.shots%every == 0 {
+		projectile.Critical = true
+	}
+	return projectile
 }
 
-// CanPlaceTower ensures tower placement is not on the path
+// CanPlaceTower ensures the tower does not cover the path. Buildable
+// regions are checked separately with Map.IsBuildable.
 func CanPlaceTower(centerX, centerY float32, m gamemap.Map) bool {
 	const halfSize = config.TowerSize / 2
 
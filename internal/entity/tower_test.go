@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/nx23/final-path/internal/gamemap"
+)
+
+func TestNewTowerFiresRightAway(t *testing.T) {
+	m := gamemap.DefaultMap()
+	for _, kind := range TowerKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			tower := NewTower(100, 300, kind, 15)
+			reload := 1 / float64(tower.FireRate)
+
+			// Even well into a match, the first shot needs no reload
+			now := reload / 2
+			if !tower.CanFire(now, 1) {
+				t.Fatal("new tower cannot fire")
+			}
+
+			tower.Attack(newTestEnemy(), m)
+			tower.LastFireTime = now
+			if tower.CanFire(now+reload/2, 1) {
+				t.Error("tower fired again before reloading")
+			}
+			if !tower.CanFire(now+reload, 1) {
+				t.Error("tower not ready after reloading")
+			}
+		})
+	}
+}
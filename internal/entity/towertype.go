@@ -0,0 +1,156 @@
+package entity
+
+import "image/color"
+
+// TowerKind identifies a tower type in the TowerTypes registry
+type TowerKind string
+
+const (
+	TowerGun       TowerKind = "gun"
+	TowerCannon    TowerKind = "cannon"
+	TowerSniper    TowerKind = "sniper"
+	TowerFrost     TowerKind = "frost"
+	TowerLightning TowerKind = "lightning"
+)
+
+// TowerKinds lists every tower type in build palette order
+var TowerKinds = []TowerKind{TowerGun, TowerCannon, TowerSniper, TowerFrost, TowerLightning}
+
+// TowerUpgrade is one tier of a tower type's upgrade path. Its bonuses
+// are added to the tower's current stats.
+type TowerUpgrade struct {
+	Name     string
+	Cost     int // Coins
+	Damage   int
+	Range    float32
+	FireRate float32 // Extra shots per second
+}
+
+// TowerType holds the stats and projectile behaviour of a kind of tower.
+// Cost multiplies the current tower cost so buying slots keeps raising
+// the price of every type.
+type TowerType struct {
+	Name            string
+	Cost            float32 // Multiplier on the current tower cost
+	Range           float32
+	Damage          int
+	DamageType      DamageType
+	CritEvery       int     // Every Nth shot is a critical hit, 0 for never
+	FireRate        float32 // Shots per second
+	Projectile      ProjectileKind
+	ProjectileSpeed float32 // Pixels per second
+	ProjectileSize  float32
+	Color           color.RGBA
+
+	// Projectile behaviours (zero values disable them)
+	Pierce       int      // Ballistic only: extra enemies a shot passes through
+	SplashRadius float32  // Enemies this close to the target also take the hit
+	ChainCount   int      // Extra enemies the hit jumps to
+	ChainRange   float32  // Maximum distance of each jump
+	ChainFalloff float32  // Damage multiplier applied on every jump
+	Effects      []Effect // Status effects applied to every enemy the projectile damages
+
+	Upgrades []TowerUpgrade // Tiers bought in order from the tower info panel
+}
+
+// TowerTypes is the registry of every tower type
+var TowerTypes = map[TowerKind]TowerType{
+	TowerGun: {
+		Name:            "Gun",
+		Cost:            1,
+		Range:           100,
+		Damage:          10,
+		DamageType:      DamagePhysical,
+		FireRate:        1,
+		ProjectileSpeed: 600,
+		ProjectileSize:  5,
+		Color:           color.RGBA{0, 255, 255, 255},
+		Upgrades: []TowerUpgrade{
+			{Name: "Rapid Fire", Cost: 20, FireRate: 0.3},
+			{Name: "Heavy Rounds", Cost: 35, Damage: 6},
+			{Name: "Scope", Cost: 60, Damage: 4, Range: 30},
+		},
+	},
+	TowerCannon: {
+		Name:            "Cannon",
+		Cost:            2,
+		Range:           110,
+		Damage:          8,
+		DamageType:      DamagePhysical,
+		FireRate:        0.5,
+		Projectile:      ProjectileBallistic,
+		ProjectileSpeed: 420,
+		ProjectileSize:  8,
+		Color:           color.RGBA{255, 140, 0, 255},
+		SplashRadius:    45,
+		Effects:         []Effect{{Kind: EffectBurn, Strength: 4, Duration: 2}},
+		Upgrades: []TowerUpgrade{
+			{Name: "Big Shells", Cost: 30, Damage: 5},
+			{Name: "Long Barrel", Cost: 40, Range: 30},
+			{Name: "Autoloader", Cost: 70, FireRate: 0.25},
+		},
+	},
+	TowerSniper: {
+		Name:            "Sniper",
+		Cost:            2,
+		Range:           250,
+		Damage:          30,
+		DamageType:      DamagePhysical,
+		CritEvery:       4,
+		FireRate:        0.4,
+		Projectile:      ProjectileBallistic,
+		ProjectileSpeed: 1200,
+		ProjectileSize:  3,
+		Color:           color.RGBA{200, 200, 200, 255},
+		Pierce:          2,
+		Effects:         []Effect{{Kind: EffectShred, Strength: 2, Duration: 5}},
+		Upgrades: []TowerUpgrade{
+			{Name: "Hollow Points", Cost: 35, Damage: 15},
+			{Name: "Bolt Action", Cost: 50, FireRate: 0.15},
+			{Name: "Marksman", Cost: 80, Damage: 20, Range: 50},
+		},
+	},
+	TowerFrost: {
+		Name:            "Frost",
+		Cost:            1.5,
+		Range:           90,
+		Damage:          2,
+		DamageType:      DamageIce,
+		FireRate:        1.2,
+		ProjectileSpeed: 480,
+		ProjectileSize:  5,
+		Color:           color.RGBA{120, 200, 255, 255},
+		Effects:         []Effect{{Kind: EffectSlow, Strength: 0.5, Duration: 1.5}},
+		Upgrades: []TowerUpgrade{
+			{Name: "Chill", Cost: 20, Range: 20},
+			{Name: "Ice Shards", Cost: 25, Damage: 3},
+			{Name: "Blizzard", Cost: 50, FireRate: 0.5, Range: 20},
+		},
+	},
+	TowerLightning: {
+		Name:            "Lightning",
+		Cost:            2.5,
+		Range:           120,
+		Damage:          8,
+		DamageType:      DamageMagic,
+		FireRate:        0.7,
+		ProjectileSpeed: 960,
+		ProjectileSize:  4,
+		Color:           color.RGBA{255, 255, 120, 255},
+		ChainCount:      3,
+		ChainRange:      90,
+		ChainFalloff:    0.7,
+		Effects:         []Effect{{Kind: EffectStun, Duration: 0.2}},
+		Upgrades: []TowerUpgrade{
+			{Name: "Capacitor", Cost: 35, Damage: 4},
+			{Name: "Conductor", Cost: 45, Range: 25},
+			{Name: "Overcharge", Cost: 80, Damage: 4, FireRate: 0.3},
+		},
+	},
+}
+
+// LookupTowerType returns the registered type for kind
+func LookupTowerType(kind TowerKind) (TowerType, bool) {
+	towerType, ok := TowerTypes[kind]
+	return towerType, ok
+}
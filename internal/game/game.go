@@ -1,367 +1,516 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/nx23/final-path/internal/clock"
 	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/difficulty"
 	"github.com/nx23/final-path/internal/entity"
 	"github.com/nx23/final-path/internal/gamemap"
 	"github.com/nx23/final-path/internal/gameover"
 	"github.com/nx23/final-path/internal/hud"
 	"github.com/nx23/final-path/internal/instructions"
+	"github.com/nx23/final-path/internal/mapselect"
+	"github.com/nx23/final-path/internal/pausemenu"
 	"github.com/nx23/final-path/internal/renderer"
-	"github.com/nx23/final-path/internal/shop"
-	"github.com/nx23/final-path/internal/utils"
+	"github.com/nx23/final-path/internal/save"
+	"github.com/nx23/final-path/internal/scene"
+	"github.com/nx23/final-path/internal/scoreboard"
+	"github.com/nx23/final-path/internal/scores"
+	"github.com/nx23/final-path/internal/settings"
+	"github.com/nx23/final-path/internal/sim"
+	"github.com/nx23/final-path/internal/towerpanel"
 )
 
+// errorDuration is how long an error message stays on screen, in seconds
+const errorDuration = 2
+
+var (
+	ErrUnknownMap = errors.New("Save is for a map that is not available!")
+	ErrBadSave    = errors.New("Save is damaged and cannot be loaded!")
+)
+
+// Game is the Ebiten adapter around the simulation.
+// It turns mouse input into sim commands and draws the resulting state.
+// The simulation runs on its own fixed step, so it plays at the same
+// speed whatever the Ebiten TPS is.
 type Game struct {
-	maps                   []gamemap.Map
-	enemies                []*entity.Enemy
-	towers                 []entity.Tower
-	projectiles            []entity.Projectile
-	towerLimit             int
-	towerCost              int
-	towerRefund            int
-	enemiesDefeated        int
-	difficultyModifier     int
-	mousePressed           bool
-	mouseRightPressed      bool
-	tick                   int
-	errorMessage           string
-	errorTimer             int
-	hud                    *hud.HUD
-	enemiesPerWave         int
-	enemiesSpawnedInWave   int
-	lastSpawnTick          int
-	spawnInterval          int
-	lives                  int
-	coins                  int
-	towerDamageBoost       int
-	towerDamageBoostCost   int
-	towerFireRateBoost     float32
-	towerFireRateBoostCost int
-	shop                   *shop.Shop
-	gameOverScreen         *gameover.GameOver
-	instructionsScreen     *instructions.Instructions
+	maps            []gamemap.Map
+	settings        settings.Settings
+	sim             *sim.Simulation
+	clock           *clock.Clock
+	commands        []sim.Command   // Input waiting for the next simulation step
+	armedAbility    sim.AbilityKind // Aimed ability fired by the next click on the map, "" if none
+	paused          bool
+	speed           int // Simulation steps run per frame of real time
+	errorMessage    string
+	errorTimer      float64 // Seconds left on screen
+	scenes          *scene.Stack
+	hud             *hud.HUD
+	mapSelectScreen *mapselect.MapSelect
+	towerPanel      *towerpanel.Panel
 }
 
-// NewGame initializes a new game with the default map
-func NewGame() *Game {
-	gameMap := gamemap.DefaultMap()
+// NewGame initializes a new game with the player's settings, offering the
+// given maps on the map select screen and falling back to the built-in
+// maps when none are given
+func NewGame(prefs settings.Settings, maps ...gamemap.Map) *Game {
+	if len(maps) == 0 {
+		maps = gamemap.BuiltinMaps()
+	}
 
 	g := &Game{
-		maps:               []gamemap.Map{gameMap},
-		enemies:            []*entity.Enemy{},
-		towerLimit:         config.GameConstants.TowerLimit,
-		towerCost:          config.GameConstants.InitialTowerCost,
-		towerRefund:        config.GameConstants.InitialTowerRefund,
-		enemiesDefeated:    config.GameConstants.EnemiesDefeated,
-		difficultyModifier: config.GameConstants.DifficultyModifier,
-		hud:                hud.NewHUD(config.GameConstants.TowerLimit, config.GameConstants.InitialTowerCost, config.GameConstants.InitialTowerRefund, config.GameConstants.InitialLives, config.GameConstants.InitialCoins),
-		spawnInterval:      config.GameConstants.SpawnInterval,
-		lives:              config.GameConstants.InitialLives,
-		coins:              config.GameConstants.InitialCoins,
-		towerDamageBoost:   config.GameConstants.TowerDamageBoost,
-		towerFireRateBoost: config.GameConstants.TowerFireRateBoost,
-		shop:               shop.NewShop(),
-		gameOverScreen:     gameover.NewGameOver(),
-		instructionsScreen: instructions.NewInstructions(),
+		maps:            maps,
+		settings:        prefs,
+		sim:             sim.New(maps[0], difficulty.Normal),
+		clock:           clock.New(config.GameConstants.TickRate),
+		speed:           1,
+		hud:             hud.NewHUD(config.GameConstants.TowerLimit, config.GameConstants.InitialTowerCost, sellPercent(), config.GameConstants.InitialLives, config.GameConstants.InitialCoins),
+		mapSelectScreen: mapselect.NewMapSelect(maps),
+		towerPanel:      towerpanel.NewPanel(),
 	}
 
+	g.sim.Logger = logMatch
+	_, g.mapSelectScreen.CanContinue = save.Latest()
+
+	// The instructions close onto the map select screen, which closes
+	// onto the match
+	g.scenes = scene.NewStack(
+		matchScene{g: g},
+		mapSelectScene{g: g},
+		instructionsScene{g: g, screen: instructions.NewInstructions()},
+	)
+
 	return g
 }
 
 func (g *Game) Update() error {
-	g.tick++
-
-	if g.instructionsScreen.Active {
-		if g.instructionsScreen.Update() {
-			// Instructions were just closed, consume the click to prevent tower placement
-			g.mousePressed = true
-		}
-		return nil
+	if err := g.scenes.Update(); err != nil {
+		return err
 	}
 
-	// Handle game over state
-	if g.gameOverScreen.Active {
-		if g.gameOverScreen.Update() {
-			g.restartGame()
+	// Count down the error message
+	if g.errorTimer > 0 {
+		g.errorTimer -= frameSeconds()
+		if g.errorTimer <= 0 {
+			g.errorMessage = ""
 		}
-		return nil
 	}
 
-	// Update enemy movement only if wave is active
-	if g.hud.WaveActive {
-		if g.enemiesSpawnedInWave < g.enemiesPerWave {
-			if g.tick-g.lastSpawnTick >= g.spawnInterval || g.enemiesSpawnedInWave == 0 {
-				g.enemies = append(g.enemies, entity.NewEnemy(entity.NewEnemyParams{
-					Map:   g.maps[0],
-					Speed: 2 * (1 + float32(g.hud.CurrentWave-1)*0.1),
-					Life:  10 + (1 + (g.hud.CurrentWave-1)*2) + (20 * g.difficultyModifier),
-				}))
-				g.enemiesSpawnedInWave++
-				g.lastSpawnTick = g.tick
-				fmt.Printf("Enemy spawned! (%d/%d)\n", g.enemiesSpawnedInWave, g.enemiesPerWave)
-			}
-		}
+	return nil
+}
 
-		// Update all enemies
-		var aliveEnemies []*entity.Enemy
-		for _, enemy := range g.enemies {
-			if enemy.IsAlive() {
-				// Check if enemy reached the end of the path
-				if enemy.CurrentPathIndex >= len(g.maps[0]) {
-					g.lives--
-					g.hud.Lives = g.lives
-					fmt.Printf("Enemy escaped! Lives remaining: %d\n", g.lives)
-
-					if g.lives <= 0 {
-						g.gameOverScreen.Activate()
-						g.hud.WaveActive = false
-						fmt.Println("Game Over!")
-					}
-				} else {
-					enemy.FollowPath(g.maps[0])
-					aliveEnemies = append(aliveEnemies, enemy)
-				}
-			} else {
-				g.enemiesDefeated++
-				g.coins += 5
-				g.hud.EnemiesDefeated = g.enemiesDefeated
-				g.hud.Coins = g.coins
-				g.hud.EnemiesKilledInWave++
-				fmt.Printf("Enemy defeated! Total: %d, Coins: %d\n", g.enemiesDefeated, g.coins)
-			}
-		}
-		g.enemies = aliveEnemies
-
-		// Check if wave is complete (all enemies spawned and all dead)
-		if g.enemiesSpawnedInWave >= g.enemiesPerWave && len(g.enemies) == 0 {
-			g.hud.WaveActive = false
-			g.hud.EnemiesKilledInWave = 0
-			// Calculate enemies for next wave
-			nextWaveEnemies := 3 + g.hud.CurrentWave*2 + (g.difficultyModifier-1)*2
-			g.hud.EnemiesInWave = nextWaveEnemies
-			fmt.Printf("Wave %d complete!\n", g.hud.CurrentWave)
-		}
+// updateMatch runs the simulation steps due this frame. Input is only
+// read while the match has focus.
+func (g *Game) updateMatch(focused bool) {
+	if focused {
+		g.handleKeyboardInput()
+		g.commands = append(g.commands, g.handleMouseInput()...)
+	}
 
-		// Check for tower attacks on all enemies
-		for i := range g.towers {
-			tower := &g.towers[i]
-			// Apply global fire rate boost
-			boostedFireRate := tower.FireRate * g.towerFireRateBoost
-			ticksPerShot := int(60.0 / boostedFireRate)
-			canFire := g.tick-tower.LastFireTime >= ticksPerShot
-
-			if canFire {
-				// Find closest enemy in range
-				for _, enemy := range g.enemies {
-					if tower.IsEnemyInRange(enemy) && enemy.IsAlive() {
-						g.projectiles = append(g.projectiles, tower.Attack(enemy))
-						g.towers[i].LastFireTime = g.tick
-						break // Only attack one enemy per tower per fire cycle
-					}
-				}
-			}
+	frame := frameSeconds()
+	waveWasActive := g.sim.WaveActive
+	if g.paused {
+		// Time stands still but building and shopping keep working
+		if err := g.sim.Apply(g.commands); err != nil {
+			g.showMessage(err.Error())
 		}
-
-		// Update projectiles
-		var activeProjectiles []entity.Projectile
-		for i := range g.projectiles {
-			projectile := &g.projectiles[i]
-			if projectile.Hit() {
-
-				if projectile.Target != nil && projectile.Target.IsAlive() {
-					totalDamage := 10 + g.towerDamageBoost
-					projectile.Target.TakeDamage(totalDamage)
-					fmt.Printf("Enemy hit! Damage: %d, Life: %d\n", totalDamage, projectile.Target.Life)
-				}
-			} else if projectile.Target != nil && projectile.Target.IsAlive() {
-				// Projectile still moving
-				activeProjectiles = append(activeProjectiles, *projectile)
+		g.commands = nil
+	} else {
+		// Run every fixed step due for this frame; input goes to the first one
+		for steps := g.clock.Advance(frame, g.speed); steps > 0; steps-- {
+			if err := g.sim.Step(g.commands); err != nil {
+				g.showMessage(err.Error())
 			}
+			g.commands = nil
 		}
-		g.projectiles = activeProjectiles
 	}
 
-	// Handle mouse input
-	g.handleMouseInput()
+	if g.sim.GameOver {
+		g.scenes.Push(gameOverScene{g: g, screen: g.recordRun()})
+	} else if waveWasActive && !g.sim.WaveActive {
+		g.autosave()
+	}
 
-	// Decrement error message timer
-	if g.errorTimer > 0 {
-		g.errorTimer--
-		if g.errorTimer == 0 {
-			g.errorMessage = ""
-		}
+	// Close the info panel once its tower is gone
+	if g.towerPanel.Open && g.selectedTower() == nil {
+		g.towerPanel.Close()
 	}
 
-	return nil
+	g.syncHUD()
 }
 
-// handleMouseInput handles all mouse interactions
-func (g *Game) handleMouseInput() {
-	mousePressedCurrent := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
-	mouseRightPressedCurrent := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+// logMatch prints the events of the match to the console
+func logMatch(message string) {
+	fmt.Println(message)
+}
 
-	if mousePressedCurrent && !g.mousePressed {
-		mx, my := ebiten.CursorPosition()
+// frameSeconds returns the real time one Update call covers
+func frameSeconds() float64 {
+	if tps := ebiten.TPS(); tps > 0 {
+		return 1 / float64(tps)
+	}
+	// Updates are synced with the display instead of a fixed TPS
+	if fps := ebiten.ActualFPS(); fps > 0 {
+		return 1 / fps
+	}
+	return 0
+}
 
-		if g.hud.IsShopButtonClicked(mx, my) {
-			g.shop.Toggle()
-			fmt.Printf("Shop %s\n", map[bool]string{true: "opened", false: "closed"}[g.shop.Open])
-		} else if g.shop.Open {
-			// Handle shop item clicks
-			g.handleShopClick(mx, my)
-		} else if g.hud.IsButtonClicked(mx, my) && !g.hud.WaveActive {
-			// Check if clicking the Next Wave button
-			g.startNextWave()
-		} else {
-			// Try to place a tower
-			g.placeTower(float32(mx), float32(my))
-		}
+// handleKeyboardInput handles the menu, save slot, game speed and ability
+// keys bound in the settings: by default Escape opens the pause menu,
+// Space toggles pause, 1, 2 and 3 pick the speeds shown in the HUD and
+// Q, W, E and R use the abilities
+func (g *Game) handleKeyboardInput() {
+	if g.keyPressed(settings.ActionMenu) {
+		g.scenes.Push(pauseMenuScene{g: g, screen: pausemenu.NewPauseMenu()})
+		return
+	}
 
+	g.handleSaveKeys()
+
+	if g.keyPressed(settings.ActionPause) {
+		g.togglePause()
 	}
 
-	// Handle right click (remove tower or close shop)
-	if mouseRightPressedCurrent && !g.mouseRightPressed {
-		if g.shop.Open {
-			g.shop.Close()
-		} else {
+	actions := []settings.Action{settings.ActionSpeed1, settings.ActionSpeed2, settings.ActionSpeed3}
+	for i, action := range actions {
+		if i < len(hud.Speeds) && g.keyPressed(action) {
+			g.setSpeed(hud.Speeds[i])
+		}
+	}
+
+	// Ability keys fire straight away, aimed ones at the cursor
+	for i, action := range abilityActions {
+		if i < len(sim.AbilityKinds) && g.keyPressed(action) {
 			mx, my := ebiten.CursorPosition()
-			g.removeTower(float32(mx), float32(my))
+			g.commands = append(g.commands, sim.UseAbility(sim.AbilityKinds[i], float32(mx), float32(my)))
+			g.armedAbility = ""
 		}
 	}
+}
 
-	// Update mouse pressed states
-	g.mousePressed = mousePressedCurrent
-	g.mouseRightPressed = mouseRightPressedCurrent
+// abilityActions are the keys using the abilities, in sim.AbilityKinds
+// order
+var abilityActions = []settings.Action{settings.ActionAbility1, settings.ActionAbility2, settings.ActionAbility3, settings.ActionAbility4}
+
+// keyPressed reports whether the key bound to action was just pressed
+func (g *Game) keyPressed(action settings.Action) bool {
+	return inpututil.IsKeyJustPressed(g.settings.Key(action))
 }
 
-// startNextWave starts the next wave of enemies
-func (g *Game) startNextWave() {
-	g.hud.CurrentWave++
-	g.hud.WaveActive = true
+// saveActions are the keys saving to the manual slots; with Shift held
+// they load from them instead
+var saveActions = []settings.Action{settings.ActionSave1, settings.ActionSave2, settings.ActionSave3}
 
-	// Set number of enemies for this wave (increases with wave number)
-	g.enemiesPerWave = 3 + (g.hud.CurrentWave-1)*2
-	g.enemiesSpawnedInWave = 0
-	g.lastSpawnTick = g.tick - g.spawnInterval - (g.hud.CurrentWave * 2)
+// handleSaveKeys saves or loads the manual slots
+func (g *Game) handleSaveKeys() {
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	for i, action := range saveActions {
+		if i >= len(save.Slots) || !g.keyPressed(action) {
+			continue
+		}
 
-	// Update difficulty modifier every 5 waves
-	if g.hud.CurrentWave%5 == 0 {
-		g.difficultyModifier++
-		fmt.Printf("Difficulty increased! Modifier: %d\n", g.difficultyModifier)
+		if shift {
+			if err := g.loadSlot(save.Slots[i]); err != nil {
+				g.showMessage(err.Error())
+			} else {
+				g.showMessage(fmt.Sprintf("Loaded slot %d", i+1))
+			}
+		} else {
+			if err := save.Write(save.Slots[i], g.sim.Snapshot()); err != nil {
+				fmt.Printf("Save failed: %v\n", err)
+				g.showMessage("Could not save the game!")
+			} else {
+				g.showMessage(fmt.Sprintf("Saved to slot %d", i+1))
+			}
+		}
 	}
+}
 
-	// Update HUD with wave info
-	g.hud.EnemiesInWave = g.enemiesPerWave
-	g.hud.EnemiesKilledInWave = 0
+// autosave writes the match to the autosave slot between waves
+func (g *Game) autosave() {
+	if err := save.Write(save.Autosave, g.sim.Snapshot()); err != nil {
+		fmt.Printf("Autosave failed: %v\n", err)
+		return
+	}
+	fmt.Println("Game autosaved")
+}
 
-	fmt.Printf("Wave %d started! (%d enemies)\n", g.hud.CurrentWave, g.enemiesPerWave)
+// continueMatch loads the most recent save
+func (g *Game) continueMatch() error {
+	slot, ok := save.Latest()
+	if !ok {
+		return save.ErrNoSave
+	}
+	return g.loadSlot(slot)
 }
 
-func (g *Game) placeTower(x, y float32) {
-	// Check if clicking in HUD area
-	if y < config.HUDHeight {
-		g.errorMessage = "Cannot place tower in HUD area!"
-		g.errorTimer = 120
-		return
+// loadSlot replaces the running match with the one saved in slot. The
+// save's map must be one of the maps offered on the map select screen.
+func (g *Game) loadSlot(slot string) error {
+	file, err := save.Read(slot)
+	if err != nil {
+		return err
 	}
 
-	// Check if there's already a tower at this position
-	for _, tower := range g.towers {
-		dx := x - tower.PositionX
-		dy := y - tower.PositionY
-		distance := dx*dx + dy*dy
-		minDistance := config.TowerSize * config.TowerSize
-
-		if distance < minDistance {
-			g.errorMessage = "Cannot place tower on another tower!"
-			g.errorTimer = 120
-			return
+	for i, m := range g.maps {
+		if m.Name != file.Match.Map {
+			continue
+		}
+		restored, err := sim.Restore(m, file.Match)
+		if err != nil {
+			fmt.Printf("Cannot restore %s: %v\n", slot, err)
+			return ErrBadSave
 		}
-	}
 
-	// Check if tower limit reached
-	if len(g.towers) >= g.towerLimit {
-		g.errorMessage = "Tower limit reached! Buy more slots in the shop."
-		g.errorTimer = 120
-		return
+		g.sim = restored
+		g.sim.Logger = logMatch
+		g.mapSelectScreen.Selected = i
+		g.mapSelectScreen.Difficulty = g.sim.Difficulty
+		// The save may have been played at another tick rate
+		g.clock = clock.New(g.sim.TickRate)
+		g.commands = nil
+		g.armedAbility = ""
+		g.towerPanel.Close()
+		fmt.Printf("Loaded %s (wave %d)\n", slot, g.sim.CurrentWave)
+		return nil
 	}
 
-	// Check if has enough coins
-	if g.coins < g.hud.TowerCost {
-		g.errorMessage = "Not enough coins to place tower!"
-		g.errorTimer = 120
-		return
+	return ErrUnknownMap
+}
+
+// recordRun adds the finished match to the run history and returns the
+// game over screen showing the high scores
+func (g *Game) recordRun() *gameover.GameOver {
+	screen := gameover.NewGameOver()
+
+	runs, err := scores.Record(scores.Run{
+		Map:         g.sim.Map.Name,
+		Preset:      g.sim.Difficulty,
+		Difficulty:  g.sim.DifficultyModifier,
+		Wave:        g.sim.CurrentWave,
+		Kills:       g.sim.EnemiesDefeated,
+		CoinsEarned: g.sim.CoinsEarned,
+		Duration:    g.sim.Time(),
+		FinishedAt:  time.Now(),
+	})
+	if err != nil {
+		fmt.Printf("Cannot record run: %v\n", err)
+		return screen
 	}
+	screen.HighScores = scores.Top(runs, scoreboard.Size)
+	return screen
+}
 
-	// Validate tower placement (not on path)
-	if !entity.CanPlaceTower(x, y, g.maps[0]) {
-		g.errorMessage = "Cannot place tower on path!"
-		g.errorTimer = 120
-		return
+// highScores returns the best recorded runs, none when the history
+// cannot be read
+func (g *Game) highScores() []scores.Run {
+	runs, err := scores.Load()
+	if err != nil {
+		fmt.Printf("Cannot load scores: %v\n", err)
+		return nil
 	}
+	return scores.Top(runs, scoreboard.Size)
+}
 
-	// Deduct coins and place tower
-	g.coins -= g.hud.TowerCost
-	g.hud.Coins = g.coins
-	fmt.Printf("Tower placed at (%.1f, %.1f)! Coins left: %d\n", x, y, g.coins)
-	g.towers = append(g.towers, entity.NewTower(x, y))
-	g.hud.TowersBuilt = len(g.towers)
+// applySettings switches to new settings, applying the display ones
+func (g *Game) applySettings(prefs settings.Settings) {
+	g.settings = prefs
+	prefs.Apply()
 }
 
-func (g *Game) removeTower(x, y float32) {
-	if y < config.HUDHeight {
-		return
+// togglePause stops or resumes the simulation clock
+func (g *Game) togglePause() {
+	g.paused = !g.paused
+	fmt.Printf("Game %s\n", map[bool]string{true: "paused", false: "resumed"}[g.paused])
+}
+
+// setSpeed changes how many simulation steps run per frame and resumes
+// the game
+func (g *Game) setSpeed(speed int) {
+	g.speed = speed
+	g.paused = false
+	fmt.Printf("Game speed: %dx\n", speed)
+}
+
+// handleMouseInput turns mouse interactions into simulation commands
+func (g *Game) handleMouseInput() []sim.Command {
+	var commands []sim.Command
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		mx, my := ebiten.CursorPosition()
+
+		if g.hud.IsShopButtonClicked(mx, my) {
+			g.scenes.Push(shopScene{g: g})
+			fmt.Println("Shop opened")
+		} else if speed, ok := g.hud.SpeedControlAt(mx, my); ok {
+			// Pause button reports speed 0
+			if speed == 0 {
+				g.togglePause()
+			} else {
+				g.setSpeed(speed)
+			}
+		} else if g.towerPanel.Contains(mx, my) {
+			// Handle tower info panel buttons
+			switch g.towerPanel.HandleClick(mx, my) {
+			case towerpanel.ActionUpgrade:
+				commands = append(commands, sim.UpgradeTower(g.towerPanel.TowerX, g.towerPanel.TowerY))
+			case towerpanel.ActionTargeting:
+				if tower := g.selectedTower(); tower != nil {
+					commands = append(commands, sim.SetTargeting(tower.PositionX, tower.PositionY, tower.Targeting.Next()))
+				}
+			case towerpanel.ActionSell:
+				commands = append(commands, sim.SellTower(g.towerPanel.TowerX, g.towerPanel.TowerY))
+				g.towerPanel.Close()
+			}
+		} else if g.hud.IsButtonClicked(mx, my) && !g.sim.WaveActive {
+			// Check if clicking the Next Wave button
+			commands = append(commands, sim.StartWave())
+		} else if index, ok := g.hud.PaletteIndexAt(mx, my); ok {
+			// Choose which tower the next click places
+			g.hud.SelectedTower = index
+		} else if index, ok := g.hud.AbilityIndexAt(mx, my); ok {
+			// Aimed abilities wait for a click on the map, the others fire now
+			kind := sim.AbilityKinds[index]
+			if sim.AbilityTypes[kind].Aimed {
+				g.armedAbility = kind
+			} else {
+				commands = append(commands, sim.UseAbility(kind, 0, 0))
+			}
+		} else if g.armedAbility != "" {
+			// Fire the armed ability at the click
+			commands = append(commands, sim.UseAbility(g.armedAbility, float32(mx), float32(my)))
+			g.armedAbility = ""
+		} else if tower := g.sim.TowerAt(float32(mx), float32(my)); tower != nil {
+			// Open the info panel of the clicked tower
+			g.towerPanel.Show(tower.PositionX, tower.PositionY)
+		} else if g.towerPanel.Open {
+			// Clicking away closes the info panel without building
+			g.towerPanel.Close()
+		} else {
+			// Try to place a tower
+			commands = append(commands, sim.PlaceTower(float32(mx), float32(my), g.selectedTowerKind()))
+		}
+	}
+
+	// Handle right click (close tower info panel, cancel aiming)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.towerPanel.Close()
+		g.armedAbility = ""
 	}
 
-	for i, tower := range g.towers {
-		halfSize := config.TowerSize / 2
-		if x >= tower.PositionX-halfSize && x <= tower.PositionX+halfSize &&
-			y >= tower.PositionY-halfSize && y <= tower.PositionY+halfSize {
-
-			// Refund half the tower cost
-			g.coins += g.towerRefund
-			g.hud.Coins = g.coins
-
-			// Remove tower
-			g.towers[i] = g.towers[len(g.towers)-1]
-			g.towers = g.towers[:len(g.towers)-1]
-			g.hud.TowersBuilt = len(g.towers)
-			fmt.Printf("Tower removed! Remaining: %d\n", len(g.towers))
-			return
+	return commands
+}
+
+// showMessage displays a message below the HUD for two seconds
+func (g *Game) showMessage(message string) {
+	g.errorMessage = message
+	g.errorTimer = errorDuration
+}
+
+// selectedTower returns the tower shown in the info panel, or nil
+func (g *Game) selectedTower() *entity.Tower {
+	return g.sim.TowerAt(g.towerPanel.TowerX, g.towerPanel.TowerY)
+}
+
+// sellPercent returns the tower sell rate as shown by the HUD
+func sellPercent() int {
+	return int(config.GameConstants.TowerSellRate * 100)
+}
+
+// selectedTowerKind returns the tower type chosen in the build palette
+func (g *Game) selectedTowerKind() entity.TowerKind {
+	return entity.TowerKinds[g.hud.SelectedTower]
+}
+
+// syncHUD copies the simulation state shown by the HUD
+func (g *Game) syncHUD() {
+	g.hud.TowersBuilt = len(g.sim.Towers)
+	g.hud.TowersLimit = g.sim.TowerLimit
+	g.hud.TowerCost = g.sim.TowerCostFor(g.selectedTowerKind())
+	g.hud.SellPercent = int(g.sim.TowerSellRate * 100)
+	g.hud.EnemiesDefeated = g.sim.EnemiesDefeated
+	g.hud.CurrentWave = g.sim.CurrentWave
+	g.hud.WaveActive = g.sim.WaveActive
+	g.hud.EnemiesInWave = g.sim.EnemiesInWave
+	g.hud.EnemiesKilledInWave = g.sim.EnemiesKilledInWave
+	g.hud.Lives = g.sim.Lives
+	g.hud.Coins = g.sim.Coins
+	g.hud.Difficulty = g.sim.Preset().Name
+	g.hud.Paused = g.paused
+	g.hud.Speed = g.speed
+
+	g.hud.AbilityOptions = g.hud.AbilityOptions[:0]
+	for i, kind := range sim.AbilityKinds {
+		abilityType := sim.AbilityTypes[kind]
+		option := hud.AbilityOption{
+			Name:     abilityType.Name,
+			Cooldown: g.sim.CooldownLeft(kind),
+			Progress: 1,
+			Charges:  g.sim.ChargesLeft(kind),
+			Armed:    kind == g.armedAbility,
+		}
+		if i < len(abilityActions) {
+			option.Key = g.settings.Key(abilityActions[i]).String()
+		}
+		if abilityType.Cooldown > 0 {
+			option.Progress = 1 - float32(option.Cooldown)/abilityType.Cooldown
 		}
+		g.hud.AbilityOptions = append(g.hud.AbilityOptions, option)
+	}
+
+	g.hud.TowerOptions = g.hud.TowerOptions[:0]
+	for _, kind := range entity.TowerKinds {
+		towerType := entity.TowerTypes[kind]
+		g.hud.TowerOptions = append(g.hud.TowerOptions, hud.TowerOption{
+			Name:  towerType.Name,
+			Cost:  g.sim.TowerCostFor(kind),
+			Color: towerType.Color,
+		})
 	}
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.Black, false)
+	g.scenes.Draw(screen)
 
-	renderer.DrawBuildableAreas(screen, g.maps[0])
+	// Draw error message (below HUD, larger text)
+	if g.errorMessage != "" {
+		renderer.DrawLargeText(screen, g.errorMessage, 20, float64(config.HUDHeight)+10, 1.5)
+	}
+}
 
-	g.maps[0].Draw(screen)
+// drawMatch draws the game world, the HUD and the tower info panel
+func (g *Game) drawMatch(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.Black, false)
 
-	renderer.DrawEnemies(screen, g.enemies)
+	renderer.DrawBuildableAreas(screen, g.sim.Map)
 
-	renderer.DrawTowers(screen, g.towers)
+	renderer.DrawMap(screen, g.sim.Map)
 
-	renderer.DrawProjectiles(screen, g.projectiles)
+	renderer.DrawEnemies(screen, g.sim.Enemies)
 
-	g.hud.Draw(screen)
+	renderer.DrawTowers(screen, g.sim.Towers)
 
-	g.shop.Draw(screen, g.coins, utils.DrawLargeText)
+	renderer.DrawProjectiles(screen, g.sim.Projectiles)
 
-	g.gameOverScreen.Draw(screen, g.enemiesDefeated, utils.DrawLargeText)
+	g.hud.Draw(screen)
 
-	g.instructionsScreen.Draw(screen, utils.DrawLargeText)
+	if tower := g.selectedTower(); tower != nil {
+		renderer.DrawTowerPanel(screen, g.towerPanel, tower, g.sim.Coins, tower.SellValue(g.sim.TowerSellRate))
+	}
 
-	// Draw error message (below HUD, larger text)
-	if g.errorMessage != "" {
-		utils.DrawLargeText(screen, g.errorMessage, 20, float64(config.HUDHeight)+10, 1.5)
+	// Show the area an armed ability will hit
+	if g.armedAbility != "" {
+		mx, my := ebiten.CursorPosition()
+		renderer.DrawAbilityTarget(screen, float32(mx), float32(my), sim.AbilityTypes[g.armedAbility].Radius)
 	}
 }
 
@@ -370,71 +519,21 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeigh
 	return config.Config.Width, config.Config.Height
 }
 
-// drawShop draws the shop overlay
+// restartGame starts a fresh simulation on the selected map
 func (g *Game) restartGame() {
 	fmt.Println("Restarting game...")
-	g.enemies = []*entity.Enemy{}
-	g.towers = []entity.Tower{}
-	g.projectiles = []entity.Projectile{}
-	g.enemiesDefeated = 0
-	g.lives = config.GameConstants.InitialLives
-	g.coins = config.GameConstants.InitialCoins
-	g.towerLimit = config.GameConstants.TowerLimit
-	g.towerDamageBoost = config.GameConstants.TowerDamageBoost
-	g.towerFireRateBoost = config.GameConstants.TowerFireRateBoost
-	g.difficultyModifier = 1
-	g.spawnInterval = config.GameConstants.SpawnInterval
-	g.tick = 0
-	g.enemiesPerWave = 0
-	g.enemiesSpawnedInWave = 0
-	g.lastSpawnTick = 0
+	g.sim = sim.New(g.maps[g.mapSelectScreen.Selected], g.mapSelectScreen.Difficulty)
+	g.sim.Logger = logMatch
+	g.clock = clock.New(g.sim.TickRate)
+	g.commands = nil
+	g.armedAbility = ""
+	g.paused = false
+	g.speed = 1
 	g.errorMessage = ""
 	g.errorTimer = 0
 
-	// Reset shop and game over screen
-	g.shop.Close()
-	g.shop = shop.NewShop()
-	g.gameOverScreen.Reset()
-	g.instructionsScreen.Hide()
+	g.towerPanel.Close()
 
 	// Reset HUD
-	g.hud = hud.NewHUD(g.towerLimit, config.GameConstants.InitialTowerCost, config.GameConstants.InitialTowerRefund, config.GameConstants.InitialLives, config.GameConstants.InitialCoins)
-}
-
-func (g *Game) handleShopClick(mx, my int) {
-	itemID, purchased := g.shop.HandleClick(mx, my, g.coins)
-
-	if !purchased {
-		return
-	}
-
-	// Process the purchase
-	newCoins, newTowerLimit, newDamageBoost, newFireRateBoost, success := g.shop.PurchaseItem(
-		itemID, g.coins, g.towerLimit, g.towerDamageBoost, g.towerFireRateBoost,
-	)
-
-	if success {
-		g.coins = newCoins
-		g.towerLimit = newTowerLimit
-		g.towerDamageBoost = newDamageBoost
-		g.towerFireRateBoost = newFireRateBoost
-		g.towerCost = 5 * newTowerLimit
-		g.towerRefund = 3 * newTowerLimit
-
-		// Update HUD
-		g.hud.Coins = g.coins
-		g.hud.TowersLimit = g.towerLimit
-		g.hud.TowerCost = g.towerCost
-		g.hud.TowerRefund = g.towerRefund
-
-		// Log purchase
-		switch itemID {
-		case 1:
-			fmt.Printf("Bought tower slot! New limit: %d\n", g.towerLimit)
-		case 2:
-			fmt.Printf("Tower damage increased! Total bonus: +%d\n", g.towerDamageBoost)
-		case 4:
-			fmt.Printf("Tower fire rate increased! Multiplier: %.1fx\n", g.towerFireRateBoost)
-		}
-	}
+	g.hud = hud.NewHUD(g.sim.TowerLimit, config.GameConstants.InitialTowerCost, sellPercent(), g.sim.Lives, g.sim.Coins)
 }
@@ -0,0 +1,239 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/nx23/final-path/internal/gameover"
+	"github.com/nx23/final-path/internal/instructions"
+	"github.com/nx23/final-path/internal/pausemenu"
+	"github.com/nx23/final-path/internal/renderer"
+	"github.com/nx23/final-path/internal/save"
+	"github.com/nx23/final-path/internal/scene"
+	"github.com/nx23/final-path/internal/scoreboard"
+	"github.com/nx23/final-path/internal/settings"
+	"github.com/nx23/final-path/internal/settingsmenu"
+	"github.com/nx23/final-path/internal/sim"
+)
+
+// The scenes adapt the screens to the scene stack. The match is always at
+// the bottom; menus are pushed over it and popped to get back to it.
+
+// matchScene plays the match: input, simulation steps and the game world
+type matchScene struct {
+	g *Game
+}
+
+func (s matchScene) Update(focused bool) error {
+	s.g.updateMatch(focused)
+	return nil
+}
+
+func (s matchScene) Draw(screen *ebiten.Image) {
+	s.g.drawMatch(screen)
+}
+
+// shopScene is the shop, drawn over the match which keeps running
+type shopScene struct {
+	g *Game
+}
+
+func (s shopScene) Cover() scene.Cover { return scene.Overlay }
+
+func (s shopScene) Update(focused bool) error {
+	if !focused {
+		return nil
+	}
+
+	g := s.g
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		mx, my := ebiten.CursorPosition()
+		if g.hud.IsShopButtonClicked(mx, my) {
+			g.scenes.Pop()
+			fmt.Println("Shop closed")
+		} else if itemID, clicked := g.sim.Shop.HandleClick(mx, my); clicked {
+			g.commands = append(g.commands, sim.BuyItem(itemID))
+		}
+	}
+
+	// The wheel scrolls catalogs too long to show at once
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		g.sim.Shop.Scroll(-1)
+	} else if dy < 0 {
+		g.sim.Shop.Scroll(1)
+	}
+
+	// Right click closes the shop
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.scenes.Pop()
+		fmt.Println("Shop closed")
+	}
+	return nil
+}
+
+func (s shopScene) Draw(screen *ebiten.Image) {
+	renderer.DrawShop(screen, s.g.sim.Shop, s.g.sim.Coins)
+}
+
+// gameOverScene is shown over the frozen match once it is lost
+type gameOverScene struct {
+	g      *Game
+	screen *gameover.GameOver
+}
+
+func (s gameOverScene) Cover() scene.Cover { return scene.Modal }
+
+func (s gameOverScene) Update(focused bool) error {
+	if focused && s.screen.Update() {
+		// Back to the match alone, closing the shop if it was open
+		s.g.scenes.PopTo(1)
+		s.g.restartGame()
+	}
+	return nil
+}
+
+func (s gameOverScene) Draw(screen *ebiten.Image) {
+	s.screen.Draw(screen, s.g.sim.EnemiesDefeated, renderer.DrawLargeText)
+}
+
+// instructionsScene is the how-to-play screen shown on startup
+type instructionsScene struct {
+	g      *Game
+	screen *instructions.Instructions
+}
+
+func (s instructionsScene) Update(focused bool) error {
+	if focused && s.screen.Update() {
+		s.g.scenes.Pop()
+	}
+	return nil
+}
+
+func (s instructionsScene) Draw(screen *ebiten.Image) {
+	s.screen.Draw(screen, renderer.DrawLargeText)
+}
+
+// mapSelectScene picks the map of a new match, continues a saved one or
+// opens the high scores
+type mapSelectScene struct {
+	g *Game
+}
+
+func (s mapSelectScene) Update(focused bool) error {
+	g := s.g
+	if !focused || !g.mapSelectScreen.Update() {
+		return nil
+	}
+
+	switch {
+	case g.mapSelectScreen.ScoresOpened:
+		g.scenes.Push(scoreboardScene{g: g, screen: scoreboard.NewScoreboard(g.highScores())})
+		return nil
+	case g.mapSelectScreen.Continued:
+		if err := g.continueMatch(); err != nil {
+			// Stay on the map select screen to pick a new match instead
+			g.mapSelectScreen.CanContinue = false
+			fmt.Printf("Cannot continue: %v\n", err)
+			return nil
+		}
+	default:
+		g.restartGame()
+	}
+
+	g.syncHUD()
+	g.scenes.Pop()
+	return nil
+}
+
+func (s mapSelectScene) Draw(screen *ebiten.Image) {
+	s.g.mapSelectScreen.Draw(screen, renderer.DrawLargeText)
+}
+
+// scoreboardScene lists the high scores, opened from the map select screen
+type scoreboardScene struct {
+	g      *Game
+	screen *scoreboard.Scoreboard
+}
+
+func (s scoreboardScene) Update(focused bool) error {
+	if focused && s.screen.Update() {
+		s.g.scenes.Pop()
+	}
+	return nil
+}
+
+func (s scoreboardScene) Draw(screen *ebiten.Image) {
+	s.screen.Draw(screen, renderer.DrawLargeText)
+}
+
+// pauseMenuScene is the pause menu, shown over the frozen match
+type pauseMenuScene struct {
+	g      *Game
+	screen *pausemenu.PauseMenu
+}
+
+func (s pauseMenuScene) Cover() scene.Cover { return scene.Modal }
+
+func (s pauseMenuScene) Update(focused bool) error {
+	if !focused {
+		return nil
+	}
+
+	g := s.g
+	if g.keyPressed(settings.ActionMenu) {
+		g.scenes.Pop()
+		return nil
+	}
+
+	switch s.screen.Update() {
+	case pausemenu.ChoiceResume:
+		g.scenes.Pop()
+	case pausemenu.ChoiceSettings:
+		g.scenes.Push(settingsScene{g: g, screen: settingsmenu.NewSettingsMenu(g.settings.Clone())})
+	case pausemenu.ChoiceRestart:
+		g.scenes.PopTo(1)
+		g.restartGame()
+	case pausemenu.ChoiceQuit:
+		// Leave the match for the map select screen
+		g.scenes.PopTo(1)
+		_, g.mapSelectScreen.CanContinue = save.Latest()
+		g.scenes.Push(mapSelectScene{g: g})
+		fmt.Println("Quit to map select")
+	}
+	return nil
+}
+
+func (s pauseMenuScene) Draw(screen *ebiten.Image) {
+	s.screen.Draw(screen, renderer.DrawLargeText)
+}
+
+// settingsScene edits the settings, applying each change as it is made
+// and saving them on the way out
+type settingsScene struct {
+	g      *Game
+	screen *settingsmenu.SettingsMenu
+}
+
+func (s settingsScene) Update(focused bool) error {
+	if !focused {
+		return nil
+	}
+
+	g := s.g
+	switch s.screen.Update() {
+	case settingsmenu.ResultChanged:
+		g.applySettings(s.screen.Settings.Clone())
+	case settingsmenu.ResultBack:
+		if err := settings.Save(g.settings); err != nil {
+			fmt.Printf("Cannot save settings: %v\n", err)
+			g.showMessage("Could not save the settings!")
+		}
+		g.scenes.Pop()
+	}
+	return nil
+}
+
+func (s settingsScene) Draw(screen *ebiten.Image) {
+	s.screen.Draw(screen, renderer.DrawLargeText)
+}
@@ -0,0 +1,65 @@
+package gamemap
+
+// Lane curve modes
+const (
+	CurveLinear     = "linear"      // Straight segments between points (default)
+	CurveCatmullRom = "catmull-rom" // Smooth curve through every segment end point
+)
+
+// curveSteps is how many straight pieces each smoothed segment is split into
+const curveSteps = 8
+
+// Smoothed returns the lane with its segments replaced by a Catmull-Rom
+// spline through the same points, approximated by short straight segments.
+// The spline can swing past its points near sharp corners, so it is
+// clamped to a width x height play area. Lanes that are not
+// CurveCatmullRom are returned unchanged.
+func (l Lane) Smoothed(width, height float32) Lane {
+	if l.Curve != CurveCatmullRom || len(l.Paths) < 2 {
+		return l
+	}
+
+	points := make([][2]float32, 0, len(l.Paths)+1)
+	points = append(points, [2]float32{l.Paths[0].StartX, l.Paths[0].StartY})
+	for _, path := range l.Paths {
+		points = append(points, [2]float32{path.EndX, path.EndY})
+	}
+
+	smoothed := Lane{Name: l.Name, Curve: l.Curve}
+	for i := 0; i < len(points)-1; i++ {
+		// Clamp the outer control points at both ends of the lane
+		p0 := points[max(i-1, 0)]
+		p1 := points[i]
+		p2 := points[i+1]
+		p3 := points[min(i+2, len(points)-1)]
+
+		prevX, prevY := p1[0], p1[1]
+		for step := 1; step <= curveSteps; step++ {
+			t := float32(step) / curveSteps
+			x := min(max(catmullRom(p0[0], p1[0], p2[0], p3[0], t), 0), width)
+			y := min(max(catmullRom(p0[1], p1[1], p2[1], p3[1], t), 0), height)
+			if step == curveSteps {
+				// Land exactly on the original point so lanes stay contiguous
+				x, y = p2[0], p2[1]
+			}
+			if x == prevX && y == prevY {
+				// Clamped onto the previous point; skip the empty piece
+				continue
+			}
+			smoothed.Paths = append(smoothed.Paths, Path{StartX: prevX, StartY: prevY, EndX: x, EndY: y})
+			prevX, prevY = x, y
+		}
+	}
+
+	return smoothed
+}
+
+// catmullRom evaluates a uniform Catmull-Rom spline between p1 and p2
+func catmullRom(p0, p1, p2, p3, t float32) float32 {
+	t2 := t * t
+	t3 := t2 * t
+	return 0.5 * ((2 * p1) +
+		(-p0+p2)*t +
+		(2*p0-5*p1+4*p2-p3)*t2 +
+		(-p0+3*p1-3*p2+p3)*t3)
+}
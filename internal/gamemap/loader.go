@@ -0,0 +1,255 @@
+package gamemap
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/utils"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// File is the on-disk map format. Files ending in .yaml/.yml are read as
+// YAML, anything else as JSON. Coordinates are relative to the top-left of
+// the play area (below the HUD), which is 800x600 with the default window.
+//
+//	{
+//	  "name": "Switchback",
+//	  "background": "#101010",
+//	  "paths": [
+//	    {"startX": 350, "startY": 0, "endX": 350, "endY": 150},
+//	    {"startX": 350, "startY": 150, "endX": 550, "endY": 150}
+//	  ],
+//	  "buildable": [{"x": 0, "y": 0, "width": 800, "height": 300}],
+//	  "waves": [{"groups": [{"type": "basic", "count": 4}]}]
+//	}
+//
+// Path segments use the same top-left convention as the built-in map: a
+// segment is PathWidth wide and may point in any direction, including
+// diagonals. Each segment must start where the previous one ended.
+// "buildable" and "waves" are optional; "waves" uses the wave schedule
+// format (see wave.Load) and is played before the procedural waves.
+//
+// Maps with several routes use "lanes" instead of "paths", each with a
+// unique name and its own segments. A lane may set "curve" to
+// "catmull-rom" to turn its corners into a smooth curve through the same
+// points. A wave group may set "lane" to send its enemies down one route:
+//
+//	"lanes": [
+//	  {"name": "north", "curve": "catmull-rom", "paths": [...]},
+//	  {"name": "south", "paths": [...]}
+//	],
+//	"waves": [{"groups": [{"count": 4, "lane": "north"}]}]
+type File struct {
+	Name       string      `json:"name" yaml:"name"`
+	Background string      `json:"background" yaml:"background"` // "#RRGGBB", defaults to black
+	Paths      []Path      `json:"paths" yaml:"paths"`           // Shorthand for a single lane named "main"
+	Lanes      []Lane      `json:"lanes" yaml:"lanes"`
+	Buildable  []Region    `json:"buildable" yaml:"buildable"`
+	Waves      []wave.Wave `json:"waves" yaml:"waves"`
+}
+
+// Load reads, validates and converts a map file
+func Load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Map{}, err
+	}
+
+	var file File
+	if err := utils.Decode(path, data, &file); err != nil {
+		return Map{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if file.Name == "" {
+		file.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	m, err := file.Map()
+	if err != nil {
+		return Map{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// LoadDir loads every .json, .yaml and .yml map in a directory, sorted by
+// file name. Files that fail to load are returned as errors alongside the
+// maps that succeeded.
+func LoadDir(dir string) ([]Map, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var maps []Map
+	var errs []error
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		m, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		maps = append(maps, m)
+	}
+
+	return maps, errs
+}
+
+// Map validates the file and converts it to screen coordinates
+func (f File) Map() (Map, error) {
+	if err := f.Validate(); err != nil {
+		return Map{}, err
+	}
+
+	background := color.RGBA{0, 0, 0, 255}
+	if f.Background != "" {
+		background, _ = parseHexColor(f.Background)
+	}
+
+	width, height := playArea()
+	lanes := f.lanes()
+	for i := range lanes {
+		lanes[i] = lanes[i].Smoothed(width, height)
+	}
+
+	m := Map{
+		Name:       f.Name,
+		Lanes:      lanes,
+		Background: background,
+		Buildable:  append([]Region(nil), f.Buildable...),
+		Waves:      append([]wave.Wave(nil), f.Waves...),
+	}
+	m.offset(config.MapOffsetY)
+	return m, nil
+}
+
+// lanes returns a copy of the file's lanes, turning the "paths" shorthand
+// into a single lane named "main"
+func (f File) lanes() []Lane {
+	if len(f.Paths) > 0 {
+		return []Lane{{Name: "main", Paths: append([]Path(nil), f.Paths...)}}
+	}
+
+	lanes := make([]Lane, len(f.Lanes))
+	for i, lane := range f.Lanes {
+		lanes[i] = Lane{Name: lane.Name, Curve: lane.Curve, Paths: append([]Path(nil), lane.Paths...)}
+	}
+	return lanes
+}
+
+// Validate checks that every lane is contiguous and inside the play area, and that regions and waves are well formed
+func (f File) Validate() error {
+	width, height := playArea()
+
+	if len(f.Paths) > 0 && len(f.Lanes) > 0 {
+		return errors.New("map has both \"paths\" and \"lanes\", use one")
+	}
+
+	lanes := f.lanes()
+	if len(lanes) == 0 {
+		return errors.New("map has no path segments")
+	}
+
+	names := map[string]bool{}
+	for _, lane := range lanes {
+		if lane.Name == "" {
+			return errors.New("every lane needs a name")
+		}
+		if names[lane.Name] {
+			return fmt.Errorf("lane %q is defined twice", lane.Name)
+		}
+		names[lane.Name] = true
+
+		switch lane.Curve {
+		case "", CurveLinear, CurveCatmullRom:
+		default:
+			return fmt.Errorf("lane %q has unknown curve %q (use %q or %q)", lane.Name, lane.Curve, CurveLinear, CurveCatmullRom)
+		}
+
+		if err := validatePaths(lane.Paths, width, height); err != nil {
+			return fmt.Errorf("lane %q: %w", lane.Name, err)
+		}
+	}
+
+	for i, region := range f.Buildable {
+		if region.Width <= 0 || region.Height <= 0 {
+			return fmt.Errorf("buildable region %d has no area", i)
+		}
+		if region.X < 0 || region.Y < 0 || region.X+region.Width > width || region.Y+region.Height > height {
+			return fmt.Errorf("buildable region %d is outside the %.0fx%.0f play area", i, width, height)
+		}
+	}
+
+	laneNames := make([]string, len(lanes))
+	for i, lane := range lanes {
+		laneNames[i] = lane.Name
+	}
+	if err := (wave.Schedule{Waves: f.Waves}).Validate(laneNames, nil); err != nil {
+		return err
+	}
+
+	if f.Background != "" {
+		if _, err := parseHexColor(f.Background); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// playArea returns the size of the area below the HUD that map
+// coordinates are relative to
+func playArea() (width, height float32) {
+	return float32(config.Config.Width), float32(config.Config.Height) - config.MapOffsetY
+}
+
+// validatePaths checks one lane's segments
+func validatePaths(paths []Path, width, height float32) error {
+	if len(paths) == 0 {
+		return errors.New("no path segments")
+	}
+
+	for i, path := range paths {
+		if path.StartX == path.EndX && path.StartY == path.EndY {
+			return fmt.Errorf("segment %d has zero length", i)
+		}
+		for _, p := range [][2]float32{{path.StartX, path.StartY}, {path.EndX, path.EndY}} {
+			if p[0] < 0 || p[0] > width || p[1] < 0 || p[1] > height {
+				return fmt.Errorf("segment %d point (%.0f, %.0f) is outside the %.0fx%.0f play area", i, p[0], p[1], width, height)
+			}
+		}
+		if i > 0 {
+			prev := paths[i-1]
+			if prev.EndX != path.StartX || prev.EndY != path.StartY {
+				return fmt.Errorf("segment %d starts at (%.0f, %.0f) but segment %d ends at (%.0f, %.0f)", i, path.StartX, path.StartY, i-1, prev.EndX, prev.EndY)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseHexColor parses "#RRGGBB" into an opaque color
+func parseHexColor(s string) (color.RGBA, error) {
+	var r, g, b uint8
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	if _, err := fmt.Sscanf(s[1:], "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	return color.RGBA{r, g, b, 255}, nil
+}
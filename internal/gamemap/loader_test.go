@@ -0,0 +1,185 @@
+package gamemap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// straight is a valid single segment across the play area
+var straight = []Path{{StartX: 0, StartY: 300, EndX: 800, EndY: 300}}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file File
+		err  string
+	}{
+		{
+			name: "paths and lanes",
+			file: File{Paths: straight, Lanes: []Lane{{Name: "a", Paths: straight}}},
+			err:  `map has both "paths" and "lanes"`,
+		},
+		{name: "no segments", file: File{}, err: "map has no path segments"},
+		{name: "lane without a name", file: File{Lanes: []Lane{{Paths: straight}}}, err: "every lane needs a name"},
+		{
+			name: "duplicate lane",
+			file: File{Lanes: []Lane{{Name: "a", Paths: straight}, {Name: "a", Paths: straight}}},
+			err:  `lane "a" is defined twice`,
+		},
+		{
+			name: "unknown curve",
+			file: File{Lanes: []Lane{{Name: "a", Curve: "bezier", Paths: straight}}},
+			err:  `lane "a" has unknown curve "bezier"`,
+		},
+		{name: "lane without segments", file: File{Lanes: []Lane{{Name: "a"}}}, err: `lane "a": no path segments`},
+		{
+			name: "zero length",
+			file: File{Paths: []Path{{StartX: 10, StartY: 10, EndX: 10, EndY: 10}}},
+			err:  "segment 0 has zero length",
+		},
+		{
+			name: "outside the play area",
+			file: File{Paths: []Path{{StartX: 0, StartY: 300, EndX: 900, EndY: 300}}},
+			err:  "segment 0 point (900, 300) is outside the 800x600 play area",
+		},
+		{
+			name: "above the play area",
+			file: File{Paths: []Path{{StartX: 100, StartY: -10, EndX: 100, EndY: 300}}},
+			err:  "segment 0 point (100, -10) is outside",
+		},
+		{
+			name: "not contiguous",
+			file: File{Paths: []Path{{StartX: 0, StartY: 300, EndX: 400, EndY: 300}, {StartX: 410, StartY: 300, EndX: 800, EndY: 300}}},
+			err:  "segment 1 starts at (410, 300) but segment 0 ends at (400, 300)",
+		},
+		{
+			name: "buildable without area",
+			file: File{Paths: straight, Buildable: []Region{{X: 0, Y: 0, Width: 0, Height: 100}}},
+			err:  "buildable region 0 has no area",
+		},
+		{
+			name: "buildable outside",
+			file: File{Paths: straight, Buildable: []Region{{X: 700, Y: 0, Width: 200, Height: 100}}},
+			err:  "buildable region 0 is outside the 800x600 play area",
+		},
+		{
+			name: "wave on an unknown lane",
+			file: File{Paths: straight, Waves: []wave.Wave{{Groups: []wave.Group{{Count: 1, Lane: "north"}}}}},
+			err:  `wave 1 group 1: unknown lane "north"`,
+		},
+		{
+			name: "wave without groups",
+			file: File{Paths: straight, Waves: []wave.Wave{{}}},
+			err:  "wave 1 has no groups",
+		},
+		{name: "bad background", file: File{Paths: straight, Background: "red"}, err: `invalid color "red"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.file.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+			if _, err := tt.file.Map(); err == nil {
+				t.Error("Map accepted an invalid file")
+			}
+		})
+	}
+}
+
+func TestLoadValidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crossing.json")
+	data := `{
+  "background": "#102030",
+  "lanes": [
+    {"name": "north", "paths": [{"startX": 0, "startY": 100, "endX": 800, "endY": 100}]},
+    {"name": "south", "paths": [
+      {"startX": 0, "startY": 500, "endX": 400, "endY": 500},
+      {"startX": 400, "startY": 500, "endX": 400, "endY": 600}
+    ]}
+  ],
+  "buildable": [{"x": 0, "y": 200, "width": 800, "height": 200}],
+  "waves": [
+    {"groups": [{"type": "basic", "count": 4, "lane": "north"}]},
+    {"groups": [{"type": "fast", "count": 2, "lane": "south"}, {"count": 3}]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "crossing" {
+		t.Errorf("got name %q, want the file name", m.Name)
+	}
+	if names := m.LaneNames(); len(names) != 2 || names[0] != "north" || names[1] != "south" {
+		t.Errorf("got lanes %v, want [north south]", names)
+	}
+	if len(m.Waves) != 2 || len(m.Waves[1].Groups) != 2 || m.Waves[1].Groups[0].Lane != "south" {
+		t.Errorf("got waves %+v", m.Waves)
+	}
+	if m.Background.R != 0x10 || m.Background.G != 0x20 || m.Background.B != 0x30 {
+		t.Errorf("got background %v, want #102030", m.Background)
+	}
+
+	// Map coordinates are moved below the HUD
+	if got := m.Lanes[0].Paths[0].StartY; got != 100+config.MapOffsetY {
+		t.Errorf("got lane start y %v, want %v", got, 100+config.MapOffsetY)
+	}
+	if got := m.Buildable[0].Y; got != 200+config.MapOffsetY {
+		t.Errorf("got buildable y %v, want %v", got, 200+config.MapOffsetY)
+	}
+}
+
+func TestSmoothedStaysInPlayArea(t *testing.T) {
+	// Along the top edge, then straight down: the spline swings above y = 0
+	// before the corner
+	lane := Lane{Name: "edge", Curve: CurveCatmullRom, Paths: []Path{
+		{StartX: 0, StartY: 0, EndX: 400, EndY: 0},
+		{StartX: 400, StartY: 0, EndX: 400, EndY: 300},
+	}}
+	if y := catmullRom(0, 0, 0, 300, 0.5); y >= 0 {
+		t.Fatalf("unclamped spline at y %v does not leave the play area", y)
+	}
+
+	smoothed := lane.Smoothed(800, 600)
+	if len(smoothed.Paths) == 0 {
+		t.Fatal("no segments")
+	}
+	if err := validatePaths(smoothed.Paths, 800, 600); err != nil {
+		t.Error(err)
+	}
+
+	first, last := smoothed.Paths[0], smoothed.Paths[len(smoothed.Paths)-1]
+	if first.StartX != 0 || first.StartY != 0 || last.EndX != 400 || last.EndY != 300 {
+		t.Errorf("got lane from (%v, %v) to (%v, %v), want (0, 0) to (400, 300)", first.StartX, first.StartY, last.EndX, last.EndY)
+	}
+}
+
+func TestSmoothedLinearUnchanged(t *testing.T) {
+	lane := Lane{Name: "a", Paths: []Path{
+		{StartX: 0, StartY: 0, EndX: 400, EndY: 0},
+		{StartX: 400, StartY: 0, EndX: 400, EndY: 300},
+	}}
+	if smoothed := lane.Smoothed(800, 600); len(smoothed.Paths) != 2 {
+		t.Errorf("linear lane got %d segments, want 2", len(smoothed.Paths))
+	}
+}
+
+func TestBundledMapsLoad(t *testing.T) {
+	maps, errs := LoadDir(filepath.Join("..", "..", "maps"))
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if len(maps) == 0 {
+		t.Error("no bundled maps found")
+	}
+}
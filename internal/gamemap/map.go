@@ -2,75 +2,241 @@ package gamemap
 
 import (
 	"image/color"
+	"math"
 
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/nx23/final-path/internal/config"
 	"github.com/nx23/final-path/internal/utils"
+	"github.com/nx23/final-path/internal/wave"
 )
 
-// Path is a segment of the map path (can be vertical or horizontal)
+// Path is a straight segment of the map path, in any direction.
+// Like the rest of the path, its coordinates are the top-left corner of a
+// PathWidth square, so the centerline runs PathWidth/2 right and below them.
 type Path struct {
-	StartX float32
-	StartY float32
-	EndX   float32
-	EndY   float32
+	StartX float32 `json:"startX" yaml:"startX"`
+	StartY float32 `json:"startY" yaml:"startY"`
+	EndX   float32 `json:"endX" yaml:"endX"`
+	EndY   float32 `json:"endY" yaml:"endY"`
 }
 
-// Map is the complete path formed by multiple segments
-type Map []Path
+// Region is an axis-aligned rectangle on the map (top-left + size)
+type Region struct {
+	X      float32 `json:"x" yaml:"x"`
+	Y      float32 `json:"y" yaml:"y"`
+	Width  float32 `json:"width" yaml:"width"`
+	Height float32 `json:"height" yaml:"height"`
+}
+
+// Contains reports whether the point lies inside the region
+func (r Region) Contains(x, y float32) bool {
+	return x >= r.X && x <= r.X+r.Width && y >= r.Y && y <= r.Y+r.Height
+}
+
+// Length returns the length of the segment
+func (p Path) Length() float32 {
+	dx := p.EndX - p.StartX
+	dy := p.EndY - p.StartY
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
+}
+
+// IsAxisAligned reports whether the segment is horizontal or vertical
+func (p Path) IsAxisAligned() bool {
+	return p.StartX == p.EndX || p.StartY == p.EndY
+}
+
+// Lane is one route through the map. Enemies spawn at the start of its
+// first segment and escape after walking past the end of its last one.
+type Lane struct {
+	Name  string `json:"name" yaml:"name"`
+	Curve string `json:"curve" yaml:"curve"` // CurveLinear (default) or CurveCatmullRom
+	Paths []Path `json:"paths" yaml:"paths"`
+}
+
+// Length returns the total centerline length of the lane
+func (l Lane) Length() float32 {
+	var total float32
+	for _, path := range l.Paths {
+		total += path.Length()
+	}
+	return total
+}
+
+// PointAt returns the centerline point reached after walking distance
+// along the lane. Distances past either end are clamped to that end.
+func (l Lane) PointAt(distance float32) (x, y float32) {
+	const halfWidth = config.PathWidth / 2
+
+	if len(l.Paths) == 0 {
+		return 0, 0
+	}
+
+	for _, path := range l.Paths {
+		length := path.Length()
+		if distance <= length {
+			t := float32(0)
+			if length > 0 {
+				t = utils.Max(distance, 0) / length
+			}
+			return path.StartX + (path.EndX-path.StartX)*t + halfWidth,
+				path.StartY + (path.EndY-path.StartY)*t + halfWidth
+		}
+		distance -= length
+	}
+
+	last := l.Paths[len(l.Paths)-1]
+	return last.EndX + halfWidth, last.EndY + halfWidth
+}
+
+// Map is a playable level: one or more lanes made of path segments plus
+// where towers may be built and an optional scripted wave set.
+// All coordinates are screen coordinates (MapOffsetY already applied).
+type Map struct {
+	Name       string
+	Lanes      []Lane
+	Background color.RGBA
+	Buildable  []Region    // Empty means anywhere off the path
+	Waves      []wave.Wave // Scripted waves, played before the procedural ones
+}
 
 // DefaultMap returns the default game map
 func DefaultMap() Map {
-	offset := config.MapOffsetY
-	return Map{
-		{StartX: 350, StartY: 0 + offset, EndX: 350, EndY: 150 + offset},
-		{StartX: 350, StartY: 150 + offset, EndX: 550, EndY: 150 + offset},
-		{StartX: 550, StartY: 150 + offset, EndX: 550, EndY: 350 + offset},
-		{StartX: 550, StartY: 350 + offset, EndX: 150, EndY: 350 + offset},
-		{StartX: 150, StartY: 350 + offset, EndX: 150, EndY: 600 + offset},
+	m := Map{
+		Name:       "Default",
+		Background: color.RGBA{0, 0, 0, 255},
+		Lanes: []Lane{{
+			Name: "main",
+			Paths: []Path{
+				{StartX: 350, StartY: 0, EndX: 350, EndY: 150},
+				{StartX: 350, StartY: 150, EndX: 550, EndY: 150},
+				{StartX: 550, StartY: 150, EndX: 550, EndY: 350},
+				{StartX: 550, StartY: 350, EndX: 150, EndY: 350},
+				{StartX: 150, StartY: 350, EndX: 150, EndY: 600},
+			},
+		}},
 	}
+	m.offset(config.MapOffsetY)
+	return m
 }
 
-func (m Map) Draw(screen *ebiten.Image) {
-	for _, path := range m {
-		width := path.EndX - path.StartX
-		height := path.EndY - path.StartY
+// SerpentMap returns a longer built-in map that winds across the whole play area
+func SerpentMap() Map {
+	m := Map{
+		Name:       "Serpent",
+		Background: color.RGBA{10, 10, 30, 255},
+		Lanes: []Lane{{
+			Name: "main",
+			Paths: []Path{
+				{StartX: 0, StartY: 60, EndX: 650, EndY: 60},
+				{StartX: 650, StartY: 60, EndX: 650, EndY: 230},
+				{StartX: 650, StartY: 230, EndX: 100, EndY: 230},
+				{StartX: 100, StartY: 230, EndX: 100, EndY: 400},
+				{StartX: 100, StartY: 400, EndX: 650, EndY: 400},
+				{StartX: 650, StartY: 400, EndX: 650, EndY: 600},
+			},
+		}},
+	}
+	m.offset(config.MapOffsetY)
+	return m
+}
 
-		// Vertical path has width=0, so we use PathWidth
-		if width == 0 {
-			width = config.PathWidth
-		}
+// CrossroadsMap returns a built-in map with two lanes entering from
+// opposite sides and leaving through the bottom
+func CrossroadsMap() Map {
+	m := Map{
+		Name:       "Crossroads",
+		Background: color.RGBA{25, 10, 10, 255},
+		Lanes: []Lane{
+			{
+				Name: "west",
+				Paths: []Path{
+					{StartX: 0, StartY: 100, EndX: 250, EndY: 100},
+					{StartX: 250, StartY: 100, EndX: 250, EndY: 600},
+				},
+			},
+			{
+				Name: "east",
+				Paths: []Path{
+					{StartX: 750, StartY: 100, EndX: 500, EndY: 100},
+					{StartX: 500, StartY: 100, EndX: 500, EndY: 350},
+					{StartX: 500, StartY: 350, EndX: 650, EndY: 350},
+					{StartX: 650, StartY: 350, EndX: 650, EndY: 600},
+				},
+			},
+		},
+	}
+	m.offset(config.MapOffsetY)
+	return m
+}
 
-		// Horizontal path has height=0, so we use PathWidth
-		if height == 0 {
-			height = config.PathWidth
-		}
+// BuiltinMaps returns every map compiled into the game
+func BuiltinMaps() []Map {
+	return []Map{DefaultMap(), SerpentMap(), CrossroadsMap()}
+}
 
-		if path.StartX < path.EndX {
-			width += 50
+// offset shifts every coordinate down by dy (used to place the map below the HUD)
+func (m *Map) offset(dy float32) {
+	for l := range m.Lanes {
+		for i := range m.Lanes[l].Paths {
+			m.Lanes[l].Paths[i].StartY += dy
+			m.Lanes[l].Paths[i].EndY += dy
 		}
-		if path.StartY < path.EndY {
-			height += 50
+	}
+	for i := range m.Buildable {
+		m.Buildable[i].Y += dy
+	}
+}
+
+// LaneNames returns the name of every lane in order
+func (m Map) LaneNames() []string {
+	names := make([]string, len(m.Lanes))
+	for i, lane := range m.Lanes {
+		names[i] = lane.Name
+	}
+	return names
+}
+
+// LaneIndex returns the index of the lane with the given name
+func (m Map) LaneIndex(name string) (int, bool) {
+	for i, lane := range m.Lanes {
+		if lane.Name == name {
+			return i, true
 		}
+	}
+	return 0, false
+}
 
-		vector.FillRect(screen, path.StartX, path.StartY, width, height, color.White, false)
+// IsBuildable checks if a position is inside one of the buildable regions.
+// Maps without regions allow building anywhere.
+func (m Map) IsBuildable(x, y float32) bool {
+	if len(m.Buildable) == 0 {
+		return true
 	}
+
+	for _, region := range m.Buildable {
+		if region.Contains(x, y) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// IsPositionOnPath checks if a position is on the path.
+// IsPositionOnPath checks if a position is on the path of any lane,
+// measuring the distance to each segment's centerline.
 // Uses a 30px margin to make tower validation easier.
 func IsPositionOnPath(x, y float32, m Map) bool {
 	const margin float32 = 30
+	const halfWidth = config.PathWidth / 2
 
-	for _, path := range m {
-		minX := utils.Min(path.StartX, path.EndX) - margin
-		maxX := utils.Max(path.StartX, path.EndX) + config.PathWidth + margin
-		minY := utils.Min(path.StartY, path.EndY) - margin
-		maxY := utils.Max(path.StartY, path.EndY) + config.PathWidth + margin
+	for _, lane := range m.Lanes {
+		for _, path := range lane.Paths {
+			distance := utils.DistanceToSegment(x, y,
+				path.StartX+halfWidth, path.StartY+halfWidth,
+				path.EndX+halfWidth, path.EndY+halfWidth)
 
-		if x >= minX && x <= maxX && y >= minY && y <= maxY {
-			return true
+			if distance <= halfWidth+margin {
+				return true
+			}
 		}
 	}
 
@@ -5,35 +5,35 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/nx23/final-path/internal/scoreboard"
+	"github.com/nx23/final-path/internal/scores"
 )
 
+// highScoreRows is how many of the best runs are listed below the restart
+// button
+const highScoreRows = 5
+
 type GameOver struct {
-	Active              bool
 	RestartButtonX      float32
 	RestartButtonY      float32
 	RestartButtonWidth  float32
 	RestartButtonHeight float32
-	mousePressed        bool
+	HighScores          []scores.Run // Best runs so far, this one included
 }
 
 func NewGameOver() *GameOver {
 	return &GameOver{
-		Active:              false,
 		RestartButtonX:      300,
 		RestartButtonY:      360,
 		RestartButtonWidth:  200,
 		RestartButtonHeight: 60,
-		mousePressed:        false,
 	}
 }
 
 // Draw renders the game over screen with restart button
 func (go_screen *GameOver) Draw(screen *ebiten.Image, enemiesDefeated int, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
-	if !go_screen.Active {
-		return
-	}
-
 	// Semi-transparent dark overlay
 	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
 		color.RGBA{0, 0, 0, 200}, false)
@@ -54,30 +54,22 @@ func (go_screen *GameOver) Draw(screen *ebiten.Image, enemiesDefeated int, drawT
 
 	// Button text (centered)
 	drawTextFunc(screen, "RESTART", float64(go_screen.RestartButtonX+50), float64(go_screen.RestartButtonY+15), 2.5)
+
+	drawTextFunc(screen, "HIGH SCORES", 110, 450, 2.0)
+	scoreboard.DrawTable(screen, go_screen.HighScores[:min(highScoreRows, len(go_screen.HighScores))], 110, 490, drawTextFunc)
 }
 
 // Update handles input for the game over screen
 // Returns true if the restart button was clicked
 func (go_screen *GameOver) Update() bool {
-	if !go_screen.Active {
-		return false
-	}
-
-	// Handle mouse input
-	mousePressedCurrent := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
-
 	// Check for mouse click (press and release)
-	if !mousePressedCurrent && go_screen.mousePressed {
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
 		mx, my := ebiten.CursorPosition()
 
 		// Check if clicking the restart button
-		if go_screen.isRestartButtonClicked(mx, my) {
-			go_screen.mousePressed = false
-			return true
-		}
+		return go_screen.isRestartButtonClicked(mx, my)
 	}
 
-	go_screen.mousePressed = mousePressedCurrent
 	return false
 }
 
@@ -87,14 +79,3 @@ func (go_screen *GameOver) isRestartButtonClicked(x, y int) bool {
 	return fx >= go_screen.RestartButtonX && fx <= go_screen.RestartButtonX+go_screen.RestartButtonWidth &&
 		fy >= go_screen.RestartButtonY && fy <= go_screen.RestartButtonY+go_screen.RestartButtonHeight
 }
-
-// Activate triggers the game over screen
-func (go_screen *GameOver) Activate() {
-	go_screen.Active = true
-}
-
-// Reset deactivates the game over screen
-func (go_screen *GameOver) Reset() {
-	go_screen.Active = false
-	go_screen.mousePressed = false
-}
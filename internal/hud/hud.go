@@ -3,18 +3,40 @@ package hud
 import (
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/nx23/final-path/internal/config"
-	"github.com/nx23/final-path/internal/utils"
+	"github.com/nx23/final-path/internal/renderer"
 )
 
+// Speeds are the simulation speed multipliers offered next to the pause
+// button
+var Speeds = []int{1, 2, 4}
+
+// TowerOption is one entry of the build palette
+type TowerOption struct {
+	Name  string
+	Cost  int
+	Color color.RGBA
+}
+
+// AbilityOption is one ability button above the build palette
+type AbilityOption struct {
+	Name     string
+	Key      string  // Bound key
+	Cooldown float64 // Seconds of game time before it can be used again
+	Progress float32 // Share of the cooldown elapsed, 1 when ready
+	Charges  int     // Uses left, -1 for unlimited
+	Armed    bool    // Waiting for a click on the map to aim it
+}
+
 type HUD struct {
 	TowersBuilt         int
 	TowersLimit         int
 	TowerCost           int
-	TowerRefund         int
+	SellPercent         int // Share of a tower's investment refunded when selling it
 	EnemiesDefeated     int
 	CurrentWave         int
 	WaveActive          bool
@@ -22,6 +44,12 @@ type HUD struct {
 	EnemiesKilledInWave int
 	Lives               int
 	Coins               int
+	Difficulty          string // Name of the difficulty preset
+	TowerOptions        []TowerOption
+	SelectedTower       int // Index into TowerOptions placed by the next click
+	AbilityOptions      []AbilityOption
+	Paused              bool
+	Speed               int // Active entry of Speeds
 	buttonX             float32
 	buttonY             float32
 	buttonWidth         float32
@@ -30,14 +58,26 @@ type HUD struct {
 	shopButtonY         float32
 	shopButtonWidth     float32
 	shopButtonHeight    float32
+	paletteX            float32
+	paletteY            float32
+	paletteButtonWidth  float32
+	paletteButtonHeight float32
+	paletteGap          float32
+	abilityY            float32
+	abilityButtonHeight float32
+	speedX              float32
+	speedY              float32
+	speedButtonWidth    float32
+	speedButtonHeight   float32
+	speedGap            float32
 }
 
-func NewHUD(towerLimit int, towerCost int, towerRefund int, initialLives int, initialCoins int) *HUD {
+func NewHUD(towerLimit int, towerCost int, sellPercent int, initialLives int, initialCoins int) *HUD {
 	return &HUD{
 		TowersBuilt:         0,
 		TowersLimit:         towerLimit,
 		TowerCost:           towerCost,
-		TowerRefund:         towerRefund,
+		SellPercent:         sellPercent,
 		EnemiesDefeated:     0,
 		CurrentWave:         0,
 		WaveActive:          false,
@@ -53,6 +93,19 @@ func NewHUD(towerLimit int, towerCost int, towerRefund int, initialLives int, in
 		shopButtonY:         90,
 		shopButtonWidth:     150,
 		shopButtonHeight:    25,
+		paletteX:            9,
+		paletteY:            float32(config.Config.Height) - 42,
+		paletteButtonWidth:  150,
+		paletteButtonHeight: 34,
+		paletteGap:          8,
+		abilityY:            float32(config.Config.Height) - 42 - 34,
+		abilityButtonHeight: 28,
+		Speed:               1,
+		speedX:              620,
+		speedY:              5,
+		speedButtonWidth:    34,
+		speedButtonHeight:   25,
+		speedGap:            4,
 	}
 }
 
@@ -63,41 +116,53 @@ func (h *HUD) Draw(screen *ebiten.Image) {
 
 	// Tower info
 	towerText := fmt.Sprintf("Towers Placed: %d/%d", h.TowersBuilt, h.TowersLimit)
-	utils.DrawLargeText(screen, towerText, 20, 10, 2.0)
+	renderer.DrawLargeText(screen, towerText, 20, 10, 2.0)
 
 	// Tower costs info
 	costText := fmt.Sprintf("Tower Cost: %d coins", h.TowerCost)
-	utils.DrawLargeText(screen, costText, 20, 45, 2.0)
+	renderer.DrawLargeText(screen, costText, 20, 45, 2.0)
 
-	// Tower refund info
-	refundText := fmt.Sprintf("Tower Refund: %d coins", h.TowerRefund)
-	utils.DrawLargeText(screen, refundText, 20, 80, 2.0)
+	// Tower sell info
+	refundText := fmt.Sprintf("Sell Refund: %d%%", h.SellPercent)
+	renderer.DrawLargeText(screen, refundText, 20, 80, 2.0)
 
 	// Wave progress info (when active)
 	if h.WaveActive {
 		waveProgressText := fmt.Sprintf("Wave %d: %d/%d", h.CurrentWave, h.EnemiesKilledInWave, h.EnemiesInWave)
-		utils.DrawLargeText(screen, waveProgressText, 350, 10, 2.0)
+		renderer.DrawLargeText(screen, waveProgressText, 350, 10, 2.0)
 	}
 
 	// Next wave preview (when not active)
 	if !h.WaveActive && h.EnemiesInWave > 0 {
 		nextWaveText := fmt.Sprintf("Next Wave: %d enemies", h.EnemiesInWave)
-		utils.DrawLargeText(screen, nextWaveText, 350, 10, 2.0)
+		renderer.DrawLargeText(screen, nextWaveText, 350, 10, 2.0)
 	}
 
 	// Coins info
 	coinsText := fmt.Sprintf("Coins: %d", h.Coins)
-	utils.DrawLargeText(screen, coinsText, 350, 45, 2.0)
+	renderer.DrawLargeText(screen, coinsText, 350, 45, 2.0)
 
 	// Lives info
 	livesText := fmt.Sprintf("Lives: %d", h.Lives)
-	utils.DrawLargeText(screen, livesText, 350, 80, 2.0)
+	renderer.DrawLargeText(screen, livesText, 350, 80, 2.0)
+
+	// Difficulty preset, next to the lives
+	renderer.DrawLargeText(screen, h.Difficulty, 490, 85, 1.5)
 
 	// Draw Next Wave button
 	h.drawButton(screen)
 
 	// Draw Shop button
 	h.drawShopButton(screen)
+
+	// Draw pause and speed buttons
+	h.drawSpeedControls(screen)
+
+	// Draw tower build palette
+	h.drawPalette(screen)
+
+	// Draw ability buttons above the palette
+	h.drawAbilities(screen)
 }
 
 // drawButton draws the "Next Wave" button
@@ -126,7 +191,7 @@ func (h *HUD) drawButton(screen *ebiten.Image) {
 	vector.StrokeRect(screen, h.buttonX, h.buttonY, h.buttonWidth, h.buttonHeight, 3, color.RGBA{255, 255, 255, 255}, false)
 
 	// Draw button text (centered)
-	utils.DrawLargeText(screen, buttonText, float64(h.buttonX)+9, float64(h.buttonY)+12, 2.2)
+	renderer.DrawLargeText(screen, buttonText, float64(h.buttonX)+9, float64(h.buttonY)+12, 2.2)
 }
 
 // IsButtonClicked checks if the button was clicked at the given coordinates
@@ -147,7 +212,7 @@ func (h *HUD) drawShopButton(screen *ebiten.Image) {
 	vector.StrokeRect(screen, h.shopButtonX, h.shopButtonY, h.shopButtonWidth, h.shopButtonHeight, 2, color.RGBA{255, 255, 255, 255}, false)
 
 	// Draw button text
-	utils.DrawLargeText(screen, "SHOP", float64((h.shopButtonWidth/2)+h.shopButtonX-15), float64(h.shopButtonY), 1.5)
+	renderer.DrawLargeText(screen, "SHOP", float64((h.shopButtonWidth/2)+h.shopButtonX-15), float64(h.shopButtonY), 1.5)
 }
 
 // IsShopButtonClicked checks if the shop button was clicked
@@ -156,3 +221,145 @@ func (h *HUD) IsShopButtonClicked(x, y int) bool {
 	return fx >= h.shopButtonX && fx <= h.shopButtonX+h.shopButtonWidth &&
 		fy >= h.shopButtonY && fy <= h.shopButtonY+h.shopButtonHeight
 }
+
+// drawSpeedControls draws the pause button followed by one button per
+// speed. The active speed is highlighted, dimmed while paused.
+func (h *HUD) drawSpeedControls(screen *ebiten.Image) {
+	labels := []string{"||"}
+	for _, speed := range Speeds {
+		labels = append(labels, fmt.Sprintf("%dx", speed))
+	}
+
+	for i, label := range labels {
+		x := h.speedX + float32(i)*(h.speedButtonWidth+h.speedGap)
+		y := h.speedY
+
+		bgColor := color.RGBA{30, 30, 30, 220}
+		switch {
+		case i == 0 && h.Paused:
+			bgColor = color.RGBA{200, 0, 0, 220}
+		case i > 0 && Speeds[i-1] == h.Speed && h.Paused:
+			bgColor = color.RGBA{60, 60, 100, 220}
+		case i > 0 && Speeds[i-1] == h.Speed:
+			bgColor = color.RGBA{0, 120, 255, 220}
+		}
+		vector.FillRect(screen, x, y, h.speedButtonWidth, h.speedButtonHeight, bgColor, false)
+		vector.StrokeRect(screen, x, y, h.speedButtonWidth, h.speedButtonHeight, 1, color.RGBA{255, 255, 255, 255}, false)
+
+		renderer.DrawLargeText(screen, label, float64(x)+8, float64(y), 1.5)
+	}
+}
+
+// SpeedControlAt returns the speed button at the given coordinates: the
+// pause button reports 0, the others their entry of Speeds
+func (h *HUD) SpeedControlAt(x, y int) (int, bool) {
+	fx, fy := float32(x), float32(y)
+	if fy < h.speedY || fy > h.speedY+h.speedButtonHeight {
+		return 0, false
+	}
+
+	for i := 0; i <= len(Speeds); i++ {
+		buttonX := h.speedX + float32(i)*(h.speedButtonWidth+h.speedGap)
+		if fx < buttonX || fx > buttonX+h.speedButtonWidth {
+			continue
+		}
+		if i == 0 {
+			return 0, true
+		}
+		return Speeds[i-1], true
+	}
+	return 0, false
+}
+
+// drawPalette draws the build palette along the bottom of the play area.
+// The selected tower is outlined and unaffordable towers are dimmed.
+func (h *HUD) drawPalette(screen *ebiten.Image) {
+	for i, option := range h.TowerOptions {
+		x := h.paletteX + float32(i)*(h.paletteButtonWidth+h.paletteGap)
+		y := h.paletteY
+
+		bgColor := color.RGBA{30, 30, 30, 220}
+		if option.Cost > h.Coins {
+			bgColor = color.RGBA{70, 0, 0, 220}
+		}
+		vector.FillRect(screen, x, y, h.paletteButtonWidth, h.paletteButtonHeight, bgColor, false)
+
+		if i == h.SelectedTower {
+			vector.StrokeRect(screen, x, y, h.paletteButtonWidth, h.paletteButtonHeight, 3, color.RGBA{255, 255, 255, 255}, false)
+		} else {
+			vector.StrokeRect(screen, x, y, h.paletteButtonWidth, h.paletteButtonHeight, 1, color.RGBA{120, 120, 120, 255}, false)
+		}
+
+		// Colour swatch matching the tower
+		vector.FillRect(screen, x+8, y+9, 16, 16, option.Color, false)
+
+		label := fmt.Sprintf("%s %d", option.Name, option.Cost)
+		renderer.DrawLargeText(screen, label, float64(x)+30, float64(y)+5, 1.5)
+	}
+}
+
+// PaletteIndexAt returns the palette entry at the given coordinates
+func (h *HUD) PaletteIndexAt(x, y int) (int, bool) {
+	fx, fy := float32(x), float32(y)
+	if fy < h.paletteY || fy > h.paletteY+h.paletteButtonHeight {
+		return 0, false
+	}
+
+	for i := range h.TowerOptions {
+		buttonX := h.paletteX + float32(i)*(h.paletteButtonWidth+h.paletteGap)
+		if fx >= buttonX && fx <= buttonX+h.paletteButtonWidth {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// drawAbilities draws one button per ability, filling up as its cooldown
+// runs out. Ready abilities are green, armed ones outlined.
+func (h *HUD) drawAbilities(screen *ebiten.Image) {
+	for i, option := range h.AbilityOptions {
+		x := h.paletteX + float32(i)*(h.paletteButtonWidth+h.paletteGap)
+		y := h.abilityY
+
+		ready := option.Cooldown == 0 && option.Charges != 0
+		bgColor := color.RGBA{30, 30, 30, 220}
+		if ready {
+			bgColor = color.RGBA{0, 130, 60, 220}
+		}
+		vector.FillRect(screen, x, y, h.paletteButtonWidth, h.abilityButtonHeight, bgColor, false)
+		if !ready && option.Charges != 0 {
+			vector.FillRect(screen, x, y, h.paletteButtonWidth*option.Progress, h.abilityButtonHeight, color.RGBA{0, 80, 160, 220}, false)
+		}
+
+		if option.Armed {
+			vector.StrokeRect(screen, x, y, h.paletteButtonWidth, h.abilityButtonHeight, 3, color.RGBA{255, 255, 255, 255}, false)
+		} else {
+			vector.StrokeRect(screen, x, y, h.paletteButtonWidth, h.abilityButtonHeight, 1, color.RGBA{120, 120, 120, 255}, false)
+		}
+
+		label := fmt.Sprintf("%s %s", option.Key, option.Name)
+		switch {
+		case option.Cooldown > 0:
+			label += fmt.Sprintf(" %ds", int(math.Ceil(option.Cooldown)))
+		case option.Charges >= 0:
+			label += fmt.Sprintf(" x%d", option.Charges)
+		}
+		renderer.DrawLargeText(screen, label, float64(x)+6, float64(y)+4, 1.4)
+	}
+}
+
+// AbilityIndexAt returns the ability button at the given coordinates
+func (h *HUD) AbilityIndexAt(x, y int) (int, bool) {
+	fx, fy := float32(x), float32(y)
+	if fy < h.abilityY || fy > h.abilityY+h.abilityButtonHeight {
+		return 0, false
+	}
+
+	for i := range h.AbilityOptions {
+		buttonX := h.paletteX + float32(i)*(h.paletteButtonWidth+h.paletteGap)
+		if fx >= buttonX && fx <= buttonX+h.paletteButtonWidth {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -4,44 +4,22 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-type Instructions struct {
-	Active       bool
-	mousePressed bool
-}
+type Instructions struct{}
 
 func NewInstructions() *Instructions {
-	return &Instructions{
-		Active:       true,
-		mousePressed: false,
-	}
+	return &Instructions{}
 }
 
+// Update returns true when the player clicked to close the instructions
 func (i *Instructions) Update() bool {
-	if !i.Active {
-		return false
-	}
-
-	mousePressedCurrent := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
-
-	// Check for click to close instructions
-	if mousePressedCurrent && !i.mousePressed {
-		i.mousePressed = false
-		i.Active = false
-		return true
-	}
-
-	i.mousePressed = mousePressedCurrent
-	return false
+	return inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
 }
 
 func (i *Instructions) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
-	if !i.Active {
-		return
-	}
-
 	// Semi-transparent dark overlay
 	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
 		color.RGBA{0, 0, 0, 220}, false)
@@ -69,13 +47,15 @@ func (i *Instructions) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Imag
 	drawTextFunc(screen, "RIGHT CLICK: Remove towers", 140, 320, 1.8)
 	drawTextFunc(screen, "SHOP BUTTON: Buy upgrades with coins", 140, 345, 1.8)
 	drawTextFunc(screen, "NEXT WAVE: Start the next enemy wave", 140, 370, 1.8)
+	drawTextFunc(screen, "SPACE: Pause  1/2/3: Speed  ESC: Menu", 140, 395, 1.8)
+	drawTextFunc(screen, "Q/W/E/R: Abilities, click the map to aim", 140, 420, 1.8)
 
 	// Game mechanics
-	drawTextFunc(screen, "MECHANICS:", 120, 420, 2.2)
-	drawTextFunc(screen, "- Towers auto-attack enemies in range", 140, 450, 1.8)
-	drawTextFunc(screen, "- Earn 10 coins per enemy defeated", 140, 475, 1.8)
-	drawTextFunc(screen, "- Lose 1 life if enemy reaches the end", 140, 500, 1.8)
-	drawTextFunc(screen, "- Game over when lives reach 0", 140, 525, 1.8)
+	drawTextFunc(screen, "MECHANICS:", 120, 440, 2.2)
+	drawTextFunc(screen, "- Towers auto-attack enemies in range", 140, 465, 1.8)
+	drawTextFunc(screen, "- Earn 10 coins per enemy defeated", 140, 488, 1.8)
+	drawTextFunc(screen, "- Lose 1 life if enemy reaches the end", 140, 511, 1.8)
+	drawTextFunc(screen, "- Game over when lives reach 0", 140, 534, 1.8)
 
 	// Start button
 	buttonX := float32(250)
@@ -87,14 +67,3 @@ func (i *Instructions) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Imag
 	vector.StrokeRect(screen, buttonX, buttonY, buttonWidth, buttonHeight, 3, color.RGBA{255, 255, 255, 255}, false)
 	drawTextFunc(screen, "CLICK TO START", float64(buttonX+50), float64(buttonY+6), 2.5)
 }
-
-// Show displays the instructions screen
-func (i *Instructions) Show() {
-	i.Active = true
-}
-
-// Hide closes the instructions screen
-func (i *Instructions) Hide() {
-	i.Active = false
-	i.mousePressed = false
-}
@@ -0,0 +1,236 @@
+package mapselect
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/renderer"
+)
+
+// Card layout (3 columns x 2 rows per page)
+const (
+	columns       = 3
+	rows          = 2
+	cardWidth     = 220
+	cardHeight    = 200
+	cardGap       = 30
+	gridX         = 35
+	gridY         = 140
+	thumbScale    = 0.25
+	pageButtonY   = 620
+	pageButtonW   = 120
+	pageButtonH   = 40
+	prevButtonX   = 180
+	nextButtonX   = 500
+	mapsPerPage   = columns * rows
+	thumbnailPadX = 10
+	thumbnailPadY = 10
+	continueX     = 590
+	continueY     = 50
+	continueW     = 180
+	continueH     = 40
+	scoresX       = 30
+	scoresY       = 50
+	scoresW       = 180
+	scoresH       = 40
+	difficultyY   = 670
+	difficultyX   = 170
+	difficultyW   = 140
+	difficultyH   = 36
+	difficultyGap = 10
+)
+
+// MapSelect lets the player pick which map the match is played on and
+// at which difficulty, continue the last saved match or look at the high
+// scores
+type MapSelect struct {
+	Maps         []gamemap.Map
+	Selected     int
+	Difficulty   difficulty.Kind // Preset new matches are played on
+	CanContinue  bool            // Shows the Continue button
+	Continued    bool            // Set by Update when Continue was clicked instead of a map
+	ScoresOpened bool            // Set by Update when the Scores button was clicked
+	page         int
+	thumbnails   []*ebiten.Image
+}
+
+func NewMapSelect(maps []gamemap.Map) *MapSelect {
+	return &MapSelect{
+		Maps:       maps,
+		Difficulty: difficulty.Normal,
+	}
+}
+
+// Update handles input for the map select screen.
+// Returns true when a map was chosen (see Selected), Continue was
+// clicked (see Continued) or the high scores were asked for (see
+// ScoresOpened).
+func (m *MapSelect) Update() bool {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+
+	mx, my := ebiten.CursorPosition()
+	m.Continued = false
+	m.ScoresOpened = false
+
+	if isInside(mx, my, scoresX, scoresY, scoresW, scoresH) {
+		m.ScoresOpened = true
+		return true
+	}
+
+	if m.CanContinue && isInside(mx, my, continueX, continueY, continueW, continueH) {
+		m.Continued = true
+		fmt.Println("Continuing saved match")
+		return true
+	}
+
+	if index, ok := m.cardAt(mx, my); ok {
+		m.Selected = index
+		fmt.Printf("Map selected: %s (%s)\n", m.Maps[index].Name, difficulty.Name(m.Difficulty))
+		return true
+	}
+
+	for i, kind := range difficulty.Kinds {
+		if isInside(mx, my, difficultyButtonX(i), difficultyY, difficultyW, difficultyH) {
+			m.Difficulty = kind
+			return false
+		}
+	}
+
+	if isInside(mx, my, prevButtonX, pageButtonY, pageButtonW, pageButtonH) && m.page > 0 {
+		m.page--
+	}
+	if isInside(mx, my, nextButtonX, pageButtonY, pageButtonW, pageButtonH) && m.page < m.pageCount()-1 {
+		m.page++
+	}
+	return false
+}
+
+func (m *MapSelect) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		color.RGBA{20, 20, 20, 255}, false)
+
+	drawTextFunc(screen, "SELECT A MAP", 250, 50, 3.0)
+
+	vector.FillRect(screen, scoresX, scoresY, scoresW, scoresH, color.RGBA{0, 120, 255, 220}, false)
+	vector.StrokeRect(screen, scoresX, scoresY, scoresW, scoresH, 2, color.RGBA{255, 255, 255, 255}, false)
+	drawTextFunc(screen, "SCORES", float64(scoresX+45), float64(scoresY+8), 2.0)
+
+	if m.CanContinue {
+		vector.FillRect(screen, continueX, continueY, continueW, continueH, color.RGBA{0, 160, 60, 220}, false)
+		vector.StrokeRect(screen, continueX, continueY, continueW, continueH, 2, color.RGBA{255, 255, 255, 255}, false)
+		drawTextFunc(screen, "CONTINUE", float64(continueX+30), float64(continueY+8), 2.0)
+	}
+
+	start := m.page * mapsPerPage
+	for i := start; i < len(m.Maps) && i < start+mapsPerPage; i++ {
+		m.drawCard(screen, i, drawTextFunc)
+	}
+
+	m.drawDifficulty(screen, drawTextFunc)
+
+	if m.pageCount() > 1 {
+		drawPageButton(screen, prevButtonX, "PREV", m.page > 0, drawTextFunc)
+		drawPageButton(screen, nextButtonX, "NEXT", m.page < m.pageCount()-1, drawTextFunc)
+		drawTextFunc(screen, fmt.Sprintf("%d/%d", m.page+1, m.pageCount()), 375, pageButtonY+10, 2.0)
+	}
+}
+
+// drawCard draws a map thumbnail and its name
+func (m *MapSelect) drawCard(screen *ebiten.Image, index int, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	x, y := cardPosition(index % mapsPerPage)
+
+	vector.FillRect(screen, x, y, cardWidth, cardHeight, color.RGBA{40, 40, 40, 255}, false)
+	vector.StrokeRect(screen, x, y, cardWidth, cardHeight, 3, color.RGBA{0, 120, 255, 255}, false)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(thumbScale, thumbScale)
+	op.GeoM.Translate(float64(x+thumbnailPadX), float64(y+thumbnailPadY))
+	screen.DrawImage(m.thumbnail(index), op)
+
+	drawTextFunc(screen, m.Maps[index].Name, float64(x+thumbnailPadX), float64(y+cardHeight-35), 2.0)
+}
+
+// drawDifficulty draws the preset buttons, the chosen one highlighted
+func (m *MapSelect) drawDifficulty(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	drawTextFunc(screen, "DIFFICULTY", gridX, difficultyY+10, 1.8)
+
+	for i, kind := range difficulty.Kinds {
+		x := difficultyButtonX(i)
+		buttonColor := color.RGBA{100, 100, 100, 200}
+		if kind == m.Difficulty {
+			buttonColor = color.RGBA{0, 120, 255, 220}
+		}
+		vector.FillRect(screen, x, difficultyY, difficultyW, difficultyH, buttonColor, false)
+		vector.StrokeRect(screen, x, difficultyY, difficultyW, difficultyH, 2, color.RGBA{255, 255, 255, 255}, false)
+		drawTextFunc(screen, difficulty.Presets[kind].Name, float64(x+12), difficultyY+8, 1.8)
+	}
+}
+
+// thumbnail renders the play area of a map once and caches it
+func (m *MapSelect) thumbnail(index int) *ebiten.Image {
+	if m.thumbnails == nil {
+		m.thumbnails = make([]*ebiten.Image, len(m.Maps))
+	}
+
+	if m.thumbnails[index] == nil {
+		full := ebiten.NewImage(config.Config.Width, config.Config.Height)
+		renderer.DrawBuildableAreas(full, m.Maps[index])
+		renderer.DrawMap(full, m.Maps[index])
+		playArea := image.Rect(0, int(config.MapOffsetY), config.Config.Width, config.Config.Height)
+		m.thumbnails[index] = full.SubImage(playArea).(*ebiten.Image)
+	}
+
+	return m.thumbnails[index]
+}
+
+// cardAt returns the map index under the cursor on the current page
+func (m *MapSelect) cardAt(mx, my int) (int, bool) {
+	start := m.page * mapsPerPage
+	for i := start; i < len(m.Maps) && i < start+mapsPerPage; i++ {
+		x, y := cardPosition(i % mapsPerPage)
+		if isInside(mx, my, x, y, cardWidth, cardHeight) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (m *MapSelect) pageCount() int {
+	return (len(m.Maps) + mapsPerPage - 1) / mapsPerPage
+}
+
+// cardPosition returns the top-left corner of a card slot on the page
+func cardPosition(slot int) (float32, float32) {
+	col := slot % columns
+	row := slot / columns
+	return float32(gridX + col*(cardWidth+cardGap)), float32(gridY + row*(cardHeight+cardGap))
+}
+
+// difficultyButtonX returns the left edge of the i-th preset button
+func difficultyButtonX(i int) float32 {
+	return float32(difficultyX + i*(difficultyW+difficultyGap))
+}
+
+func drawPageButton(screen *ebiten.Image, x float32, label string, enabled bool, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	buttonColor := color.RGBA{100, 100, 100, 200}
+	if enabled {
+		buttonColor = color.RGBA{0, 120, 255, 220}
+	}
+	vector.FillRect(screen, x, pageButtonY, pageButtonW, pageButtonH, buttonColor, false)
+	vector.StrokeRect(screen, x, pageButtonY, pageButtonW, pageButtonH, 2, color.RGBA{255, 255, 255, 255}, false)
+	drawTextFunc(screen, label, float64(x+30), float64(pageButtonY+8), 2.0)
+}
+
+func isInside(mx, my int, x, y, width, height float32) bool {
+	fx, fy := float32(mx), float32(my)
+	return fx >= x && fx <= x+width && fy >= y && fy <= y+height
+}
@@ -0,0 +1,88 @@
+package pausemenu
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Choice is the menu entry the player clicked
+type Choice int
+
+const (
+	ChoiceNone Choice = iota
+	ChoiceResume
+	ChoiceSettings
+	ChoiceRestart
+	ChoiceQuit
+)
+
+// Button layout
+const (
+	buttonX      = 275
+	firstButtonY = 250
+	buttonWidth  = 250
+	buttonHeight = 50
+	buttonGap    = 20
+)
+
+// Button is one entry of the menu
+type Button struct {
+	Choice Choice
+	Label  string
+}
+
+// PauseMenu is shown over the frozen match
+type PauseMenu struct {
+	Buttons []Button
+}
+
+func NewPauseMenu() *PauseMenu {
+	return &PauseMenu{
+		Buttons: []Button{
+			{Choice: ChoiceResume, Label: "RESUME"},
+			{Choice: ChoiceSettings, Label: "SETTINGS"},
+			{Choice: ChoiceRestart, Label: "RESTART"},
+			{Choice: ChoiceQuit, Label: "QUIT"},
+		},
+	}
+}
+
+// Update returns the entry clicked this frame, ChoiceNone if any
+func (p *PauseMenu) Update() Choice {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return ChoiceNone
+	}
+
+	mx, my := ebiten.CursorPosition()
+	fx, fy := float32(mx), float32(my)
+	for i, button := range p.Buttons {
+		y := buttonY(i)
+		if fx >= buttonX && fx <= buttonX+buttonWidth && fy >= y && fy <= y+buttonHeight {
+			return button.Choice
+		}
+	}
+	return ChoiceNone
+}
+
+func (p *PauseMenu) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	// Semi-transparent dark overlay
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		color.RGBA{0, 0, 0, 180}, false)
+
+	drawTextFunc(screen, "PAUSED", 328, 160, 4.0)
+
+	for i, button := range p.Buttons {
+		y := buttonY(i)
+		vector.FillRect(screen, buttonX, y, buttonWidth, buttonHeight, color.RGBA{0, 120, 255, 220}, false)
+		vector.StrokeRect(screen, buttonX, y, buttonWidth, buttonHeight, 2, color.RGBA{255, 255, 255, 255}, false)
+		drawTextFunc(screen, button.Label, float64(buttonX+30), float64(y+10), 2.5)
+	}
+}
+
+// buttonY returns the top of the i-th button
+func buttonY(i int) float32 {
+	return float32(firstButtonY + i*(buttonHeight+buttonGap))
+}
@@ -0,0 +1,69 @@
+package renderer
+
+import "image/color"
+
+// ColorScheme holds the colours that carry meaning on screen, so they can
+// be swapped for schemes that are easier to tell apart
+type ColorScheme struct {
+	Name            string
+	Path            color.RGBA
+	BuildableFill   color.RGBA
+	BuildableStroke color.RGBA
+	Health          color.RGBA // Health bar, life left
+	HealthLost      color.RGBA // Health bar, life lost
+	Affordable      color.RGBA // Shop items and upgrades the player can pay for
+	Unaffordable    color.RGBA
+}
+
+// ColorSchemeNames lists the colour schemes in the order the settings
+// screen offers them
+var ColorSchemeNames = []string{"classic", "colorblind", "contrast"}
+
+// ColorSchemes is the registry of colour schemes by name
+var ColorSchemes = map[string]ColorScheme{
+	"classic": {
+		Name:            "Classic",
+		Path:            color.RGBA{255, 255, 255, 255},
+		BuildableFill:   color.RGBA{0, 100, 0, 30},
+		BuildableStroke: color.RGBA{0, 150, 0, 50},
+		Health:          color.RGBA{0, 255, 0, 255},
+		HealthLost:      color.RGBA{80, 0, 0, 255},
+		Affordable:      color.RGBA{0, 100, 0, 255},
+		Unaffordable:    color.RGBA{100, 0, 0, 255},
+	},
+	// Blue and orange instead of green and red, told apart with any of the
+	// common kinds of colour blindness
+	"colorblind": {
+		Name:            "Colorblind",
+		Path:            color.RGBA{255, 255, 255, 255},
+		BuildableFill:   color.RGBA{0, 90, 180, 40},
+		BuildableStroke: color.RGBA{0, 120, 220, 70},
+		Health:          color.RGBA{60, 160, 255, 255},
+		HealthLost:      color.RGBA{120, 60, 0, 255},
+		Affordable:      color.RGBA{0, 80, 170, 255},
+		Unaffordable:    color.RGBA{170, 90, 0, 255},
+	},
+	"contrast": {
+		Name:            "High contrast",
+		Path:            color.RGBA{255, 255, 0, 255},
+		BuildableFill:   color.RGBA{0, 160, 0, 70},
+		BuildableStroke: color.RGBA{0, 255, 0, 140},
+		Health:          color.RGBA{0, 255, 0, 255},
+		HealthLost:      color.RGBA{255, 0, 0, 255},
+		Affordable:      color.RGBA{0, 140, 0, 255},
+		Unaffordable:    color.RGBA{170, 0, 0, 255},
+	},
+}
+
+// colors is the colour scheme in use
+var colors = ColorSchemes["classic"]
+
+// UseColorScheme switches to the named colour scheme. It returns false and
+// keeps the current one when there is no scheme by that name.
+func UseColorScheme(name string) bool {
+	scheme, ok := ColorSchemes[name]
+	if ok {
+		colors = scheme
+	}
+	return ok
+}
@@ -1,30 +1,68 @@
 package renderer
 
 import (
+	"fmt"
+	"image"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/nx23/final-path/internal/config"
 	"github.com/nx23/final-path/internal/entity"
 	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/shop"
+	"github.com/nx23/final-path/internal/towerpanel"
 	"github.com/nx23/final-path/internal/utils"
 )
 
+// DrawMap draws every path segment of every lane
+func DrawMap(screen *ebiten.Image, m gamemap.Map) {
+	for _, lane := range m.Lanes {
+		drawLane(screen, lane)
+	}
+}
+
+// drawLane draws the segments of a single lane.
+// Horizontal and vertical segments are drawn as rectangles with square
+// corners; any other direction as a thick line with rounded joints.
+func drawLane(screen *ebiten.Image, lane gamemap.Lane) {
+	const halfWidth = config.PathWidth / 2
+
+	for _, path := range lane.Paths {
+		if path.IsAxisAligned() {
+			x := utils.Min(path.StartX, path.EndX)
+			y := utils.Min(path.StartY, path.EndY)
+			width := utils.Max(path.StartX, path.EndX) - x + config.PathWidth
+			height := utils.Max(path.StartY, path.EndY) - y + config.PathWidth
+			vector.FillRect(screen, x, y, width, height, colors.Path, false)
+			continue
+		}
+
+		startX, startY := path.StartX+halfWidth, path.StartY+halfWidth
+		endX, endY := path.EndX+halfWidth, path.EndY+halfWidth
+		vector.StrokeLine(screen, startX, startY, endX, endY, config.PathWidth, colors.Path, false)
+		vector.FillCircle(screen, startX, startY, halfWidth, colors.Path, false)
+		vector.FillCircle(screen, endX, endY, halfWidth, colors.Path, false)
+	}
+}
+
 func DrawBuildableAreas(screen *ebiten.Image, gameMap gamemap.Map) {
 	const gridSize float32 = 40
 	screenWidth := float32(screen.Bounds().Dx())
 	screenHeight := float32(screen.Bounds().Dy())
 
+	vector.FillRect(screen, 0, config.MapOffsetY, screenWidth, screenHeight-config.MapOffsetY, gameMap.Background, false)
+
 	// Draw grid of buildable areas
 	for x := float32(0); x < screenWidth; x += gridSize {
 		for y := float32(0); y < screenHeight; y += gridSize {
 			centerX := x + gridSize/2
 			centerY := y + gridSize/2
 
-			if !gamemap.IsPositionOnPath(centerX, centerY, gameMap) {
-				vector.FillRect(screen, x, y, gridSize, gridSize, color.RGBA{0, 100, 0, 30}, false)
-				vector.StrokeRect(screen, x, y, gridSize, gridSize, 1, color.RGBA{0, 150, 0, 50}, false)
+			if gameMap.IsBuildable(centerX, centerY) && !gamemap.IsPositionOnPath(centerX, centerY, gameMap) {
+				vector.FillRect(screen, x, y, gridSize, gridSize, colors.BuildableFill, false)
+				vector.StrokeRect(screen, x, y, gridSize, gridSize, 1, colors.BuildableStroke, false)
 			}
 		}
 	}
@@ -33,23 +71,240 @@ func DrawBuildableAreas(screen *ebiten.Image, gameMap gamemap.Map) {
 func DrawEnemies(screen *ebiten.Image, enemies []*entity.Enemy) {
 	for _, enemy := range enemies {
 		if enemy.IsAlive() {
-			topLeftX, topLeftY := utils.CenteredPosition{X: enemy.PositionX, Y: enemy.PositionY, Size: config.EnemySize}.TopLeft()
-			vector.FillRect(screen, topLeftX, topLeftY, config.EnemySize, config.EnemySize, color.RGBA{255, 0, 0, 255}, false)
+			drawEnemy(screen, enemy)
 		}
 	}
 }
 
-func DrawTowers(screen *ebiten.Image, towers []entity.Tower) {
+// drawEnemy draws an enemy with its archetype's shape, size and colour,
+// plus a health bar once damaged and a ring while a shield is up
+func drawEnemy(screen *ebiten.Image, enemy *entity.Enemy) {
+	enemyType := enemy.Type()
+	size := enemy.Size
+	halfSize := size / 2
+	x, y := enemy.PositionX, enemy.PositionY
+
+	switch enemyType.Shape {
+	case entity.ShapeCircle:
+		vector.FillCircle(screen, x, y, halfSize, enemyType.Color, false)
+	case entity.ShapeDiamond:
+		var path vector.Path
+		path.MoveTo(x, y-halfSize)
+		path.LineTo(x+halfSize, y)
+		path.LineTo(x, y+halfSize)
+		path.LineTo(x-halfSize, y)
+		path.Close()
+		op := &vector.DrawPathOptions{}
+		op.ColorScale.ScaleWithColor(enemyType.Color)
+		vector.FillPath(screen, &path, nil, op)
+	default:
+		topLeftX, topLeftY := utils.CenteredPosition{X: x, Y: y, Size: size}.TopLeft()
+		vector.FillRect(screen, topLeftX, topLeftY, size, size, enemyType.Color, false)
+	}
+
+	// Armored enemies get a plated outline
+	if enemy.Armor > 0 {
+		topLeftX, topLeftY := utils.CenteredPosition{X: x, Y: y, Size: size}.TopLeft()
+		vector.StrokeRect(screen, topLeftX, topLeftY, size, size, 3, color.RGBA{220, 220, 220, 255}, false)
+	}
+
+	// Slowed enemies get an icy ring
+	if enemy.HasEffect(entity.EffectSlow) {
+		vector.StrokeCircle(screen, x, y, halfSize+3, 2, entity.EffectTypes[entity.EffectSlow].Color, false)
+	}
+
+	// One pip per active status effect, in the effect's colour
+	for i, fx := range enemy.Effects {
+		vector.FillRect(screen, x-halfSize+float32(i)*6, y+halfSize+3, 4, 4, fx.Type().Color, false)
+	}
+
+	if enemy.Shield > 0 {
+		vector.StrokeCircle(screen, x, y, halfSize+6, 3, color.RGBA{0, 200, 255, 220}, false)
+	}
+
+	if enemy.Life < enemy.MaxLife {
+		barY := y - halfSize - 6
+		ratio := float32(enemy.Life) / float32(enemy.MaxLife)
+		vector.FillRect(screen, x-halfSize, barY, size, 3, colors.HealthLost, false)
+		vector.FillRect(screen, x-halfSize, barY, size*ratio, 3, colors.Health, false)
+	}
+}
+
+// DrawTowers draws each tower in its type's colour with its range circle
+func DrawTowers(screen *ebiten.Image, towers []*entity.Tower) {
 	for _, tower := range towers {
 		// Draw range circle centered on tower
 		vector.StrokeCircle(screen, tower.PositionX, tower.PositionY, tower.Range, 2, color.RGBA{0, 0, 255, 20}, false)
 		topLeftX, topLeftY := utils.CenteredPosition{X: tower.PositionX, Y: tower.PositionY, Size: config.TowerSize}.TopLeft()
-		vector.FillRect(screen, topLeftX, topLeftY, config.TowerSize, config.TowerSize, color.RGBA{0, 255, 255, 255}, false)
+		vector.FillRect(screen, topLeftX, topLeftY, config.TowerSize, config.TowerSize, tower.Type().Color, false)
 	}
 }
 
+// DrawProjectiles draws each projectile with the size and colour of the
+// tower type that fired it. Critical hits are drawn larger with a red ring.
 func DrawProjectiles(screen *ebiten.Image, projectiles []entity.Projectile) {
 	for _, projectile := range projectiles {
-		vector.FillCircle(screen, projectile.PositionX, projectile.PositionY, config.ProjectileSize, color.RGBA{255, 255, 0, 255}, false)
+		towerType := projectile.Type()
+		size := towerType.ProjectileSize
+		if projectile.Critical {
+			size *= 1.5
+			vector.StrokeCircle(screen, projectile.PositionX, projectile.PositionY, size+2, 1, color.RGBA{255, 0, 0, 255}, false)
+		}
+		vector.FillCircle(screen, projectile.PositionX, projectile.PositionY, size, towerType.Color, false)
+	}
+}
+
+// DrawShop draws the shop overlay
+func DrawShop(screen *ebiten.Image, s *shop.Shop, coins int) {
+	// Semi-transparent overlay
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		color.RGBA{0, 0, 0, 180}, false)
+
+	// Shop panel
+	vector.FillRect(screen, s.X, s.Y, s.Width, s.Height, color.RGBA{40, 40, 40, 255}, false)
+	vector.StrokeRect(screen, s.X, s.Y, s.Width, s.Height, 3, color.RGBA{255, 165, 0, 255}, false)
+
+	// Title
+	DrawLargeText(screen, "SHOP", 360, 215, 3.0)
+
+	// Coins display
+	coinsText := fmt.Sprintf("Coins: %d", coins)
+	DrawLargeText(screen, coinsText, 340, 260, 2.0)
+
+	// Draw shop items
+	visible := s.Visible()
+	for _, item := range visible {
+		drawShopItem(screen, s, item, coins)
+	}
+	if s.CanScroll() {
+		scrollText := fmt.Sprintf("Scroll for more (%d-%d of %d)", s.First+1, s.First+len(visible), len(s.Items))
+		DrawLargeText(screen, scrollText, float64(s.X+20), float64(s.Y+s.Height-32), 1.5)
 	}
+
+	// Close instruction
+	DrawLargeText(screen, "Right-click to close", 320, float64(s.Y+s.Height+20), 1.5)
+}
+
+// drawShopItem renders a single shop item. Locked and sold out items are
+// drawn like unaffordable ones; locked ones say what unlocks them.
+func drawShopItem(screen *ebiten.Image, s *shop.Shop, item shop.ShopItem, coins int) {
+	itemX := s.X + 20
+	itemY := s.Y + item.Y
+	itemWidth := float32(360)
+	itemHeight := float32(50)
+
+	req, locked := s.Missing(item)
+	available := !locked && !item.SoldOut()
+
+	// Background color based on affordability
+	bgColor := colors.Unaffordable
+	if available && coins >= item.Price() {
+		bgColor = colors.Affordable
+	}
+
+	vector.FillRect(screen, itemX, itemY, itemWidth, itemHeight, bgColor, false)
+	vector.StrokeRect(screen, itemX, itemY, itemWidth, itemHeight, 2, color.RGBA{255, 255, 255, 255}, false)
+
+	// Item text
+	switch {
+	case locked:
+		DrawLargeText(screen, item.Name+" - locked", float64(itemX+10), float64(itemY+4), 1.8)
+		DrawLargeText(screen, "Needs "+requirementName(s, req), float64(itemX+10), float64(itemY+30), 1.2)
+	case item.SoldOut():
+		DrawLargeText(screen, item.Name+" - sold out", float64(itemX+10), float64(itemY+15), 1.8)
+	default:
+		itemText := fmt.Sprintf("%s - %d coins", item.Name, item.Price())
+		DrawLargeText(screen, itemText, float64(itemX+10), float64(itemY+15), 1.8)
+	}
+}
+
+// requirementName describes a missing requirement, as "2x Tower Damage +5"
+func requirementName(s *shop.Shop, req shop.Requirement) string {
+	name := req.Item
+	if other, ok := s.Item(req.Item); ok {
+		name = other.Name
+	}
+	if req.Count > 1 {
+		return fmt.Sprintf("%dx %s", req.Count, name)
+	}
+	return name
+}
+
+// DrawAbilityTarget outlines the area an aimed ability will hit around
+// the cursor
+func DrawAbilityTarget(screen *ebiten.Image, x, y, radius float32) {
+	vector.FillCircle(screen, x, y, radius, color.RGBA{255, 80, 0, 50}, false)
+	vector.StrokeCircle(screen, x, y, radius, 2, color.RGBA{255, 80, 0, 200}, false)
+}
+
+// DrawTowerPanel draws the info panel of the selected tower with its
+// level, stats, kill count and the targeting, upgrade and sell buttons
+func DrawTowerPanel(screen *ebiten.Image, p *towerpanel.Panel, tower *entity.Tower, coins int, sellValue int) {
+	if !p.Open || tower == nil {
+		return
+	}
+
+	// Highlight the selected tower's range
+	vector.StrokeCircle(screen, tower.PositionX, tower.PositionY, tower.Range, 2, color.RGBA{255, 255, 255, 120}, false)
+
+	// Panel
+	vector.FillRect(screen, p.X, p.Y, p.Width, p.Height, color.RGBA{40, 40, 40, 230}, false)
+	vector.StrokeRect(screen, p.X, p.Y, p.Width, p.Height, 3, tower.Type().Color, false)
+
+	textX := float64(p.X) + 10
+	textY := float64(p.Y)
+	DrawLargeText(screen, fmt.Sprintf("%s Lv %d", tower.Type().Name, tower.Level+1), textX, textY+8, 2.0)
+	DrawLargeText(screen, fmt.Sprintf("Damage: %d", tower.Damage), textX, textY+45, 1.5)
+	DrawLargeText(screen, fmt.Sprintf("Range: %.0f", tower.Range), textX, textY+67, 1.5)
+	DrawLargeText(screen, fmt.Sprintf("Rate: %.2f/s", tower.FireRate), textX, textY+89, 1.5)
+	DrawLargeText(screen, fmt.Sprintf("Kills: %d (%d dmg)", tower.Kills, tower.DamageDealt), textX, textY+111, 1.5)
+	DrawLargeText(screen, fmt.Sprintf("Invested: %d", tower.Invested), textX, textY+133, 1.5)
+
+	for _, button := range p.Buttons {
+		x, y := p.X+button.X, p.Y+button.Y
+		bgColor := color.RGBA{80, 80, 80, 255}
+		var label string
+
+		switch button.Action {
+		case towerpanel.ActionUpgrade:
+			if upgrade, ok := tower.NextUpgrade(); ok {
+				label = fmt.Sprintf("%s (%d)", upgrade.Name, upgrade.Cost)
+				if coins >= upgrade.Cost {
+					bgColor = colors.Affordable
+				} else {
+					bgColor = colors.Unaffordable
+				}
+			} else {
+				label = "Max level"
+			}
+		case towerpanel.ActionTargeting:
+			label = fmt.Sprintf("Target: %s", tower.Targeting)
+			bgColor = color.RGBA{0, 70, 140, 255}
+		case towerpanel.ActionSell:
+			label = fmt.Sprintf("Sell (+%d)", sellValue)
+			bgColor = color.RGBA{150, 90, 0, 255}
+		}
+
+		vector.FillRect(screen, x, y, button.Width, button.Height, bgColor, false)
+		vector.StrokeRect(screen, x, y, button.Width, button.Height, 2, color.RGBA{255, 255, 255, 255}, false)
+		DrawLargeText(screen, label, float64(x)+8, float64(y)+4, 1.5)
+	}
+}
+
+// DrawLargeText draws text with actual scaling for better readability
+// This is a shared utility used by HUD, Game, Shop, and GameOver screens
+func DrawLargeText(screen *ebiten.Image, text string, x, y, scale float64) {
+	// Create a temporary image to render text
+	bounds := image.Rect(0, 0, 400, 30)
+	textImg := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+
+	// Draw text on temporary image
+	ebitenutil.DebugPrint(textImg, text)
+
+	// Scale and draw the text image to the screen
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+
+	screen.DrawImage(textImg, op)
 }
@@ -0,0 +1,124 @@
+// Package save stores matches in progress as versioned JSON files in the
+// user's config directory: a few manual slots plus an autosave written
+// between waves.
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/sim"
+)
+
+// Version is the current save format. Bump it whenever sim.Snapshot
+// changes in a way older saves cannot be read as.
+const Version = 3
+
+// Autosave is the slot written automatically between waves
+const Autosave = "autosave"
+
+// Slots are the manual save slots
+var Slots = []string{"slot1", "slot2", "slot3"}
+
+var (
+	ErrNoSave  = errors.New("No save in this slot!")
+	ErrVersion = errors.New("Save was made by an incompatible version!")
+)
+
+// File is a save file
+type File struct {
+	Version int          `json:"version"`
+	SavedAt time.Time    `json:"savedAt"`
+	Match   sim.Snapshot `json:"match"`
+}
+
+// Dir returns the directory save files are kept in
+func Dir() (string, error) {
+	userDir, err := config.UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, "saves"), nil
+}
+
+func slotPath(slot string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, slot+".json"), nil
+}
+
+// Write saves a match to slot, replacing what the slot held. The file is
+// written next to the old one and renamed over it, so a crash mid-write
+// never leaves a broken save behind.
+func Write(slot string, snap sim.Snapshot) error {
+	path, err := slotPath(slot)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(File{Version: Version, SavedAt: time.Now(), Match: snap}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read loads the save in slot
+func Read(slot string) (File, error) {
+	path, err := slotPath(slot)
+	if err != nil {
+		return File{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return File{}, ErrNoSave
+	}
+	if err != nil {
+		return File{}, err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Version != Version {
+		return File{}, ErrVersion
+	}
+	return file, nil
+}
+
+// Latest returns the slot holding the most recent readable save, used by
+// "Continue"
+func Latest() (string, bool) {
+	var latest string
+	var latestTime time.Time
+
+	for _, slot := range append([]string{Autosave}, Slots...) {
+		file, err := Read(slot)
+		if err != nil {
+			continue
+		}
+		if latest == "" || file.SavedAt.After(latestTime) {
+			latest, latestTime = slot, file.SavedAt
+		}
+	}
+
+	return latest, latest != ""
+}
@@ -0,0 +1,148 @@
+// Package scene keeps the screens of the game on a stack. The top scene
+// has input focus; what happens to the scenes below it depends on how the
+// top scene covers them.
+package scene
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Scene is one screen of the game: a menu, the match or an overlay
+type Scene interface {
+	// Update runs once per frame. focused is true only for the top scene;
+	// scenes below it must not read input.
+	Update(focused bool) error
+	Draw(screen *ebiten.Image)
+}
+
+// Cover is what a scene does to the scenes below it
+type Cover int
+
+const (
+	// Opaque scenes hide the scenes below them and stop their updates
+	Opaque Cover = iota
+	// Modal scenes are drawn over the scenes below them, which are frozen
+	Modal
+	// Overlay scenes are drawn over the scenes below them, which keep
+	// running without input
+	Overlay
+)
+
+// Coverer is implemented by scenes that are not Opaque
+type Coverer interface {
+	Cover() Cover
+}
+
+// CoverOf returns how s covers the scenes below it
+func CoverOf(s Scene) Cover {
+	if c, ok := s.(Coverer); ok {
+		return c.Cover()
+	}
+	return Opaque
+}
+
+// Stack is the stack of scenes. Changes made while it updates take effect
+// once every scene has been updated, so a scene pushed by a click does not
+// see that click again.
+type Stack struct {
+	scenes   []Scene
+	pending  []func()
+	updating bool
+}
+
+func NewStack(scenes ...Scene) *Stack {
+	return &Stack{scenes: scenes}
+}
+
+// Push puts s on top of the stack, giving it input focus
+func (st *Stack) Push(s Scene) {
+	st.change(func() {
+		st.scenes = append(st.scenes, s)
+	})
+}
+
+// Pop removes the top scene, giving focus back to the one below it
+func (st *Stack) Pop() {
+	st.change(func() {
+		if len(st.scenes) > 0 {
+			st.scenes[len(st.scenes)-1] = nil
+			st.scenes = st.scenes[:len(st.scenes)-1]
+		}
+	})
+}
+
+// Replace swaps the top scene for s
+func (st *Stack) Replace(s Scene) {
+	st.Pop()
+	st.Push(s)
+}
+
+// PopTo removes scenes until only the bottom n are left
+func (st *Stack) PopTo(n int) {
+	st.change(func() {
+		for len(st.scenes) > max(n, 0) {
+			st.scenes[len(st.scenes)-1] = nil
+			st.scenes = st.scenes[:len(st.scenes)-1]
+		}
+	})
+}
+
+// Top returns the scene with input focus, nil when the stack is empty
+func (st *Stack) Top() Scene {
+	if len(st.scenes) == 0 {
+		return nil
+	}
+	return st.scenes[len(st.scenes)-1]
+}
+
+// Len returns the number of scenes on the stack
+func (st *Stack) Len() int {
+	return len(st.scenes)
+}
+
+func (st *Stack) change(apply func()) {
+	if st.updating {
+		st.pending = append(st.pending, apply)
+		return
+	}
+	apply()
+}
+
+// Update updates the top scene with focus, then the scenes below it that
+// are left running: everything under Overlay scenes down to the first
+// Opaque or Modal one.
+func (st *Stack) Update() error {
+	st.updating = true
+	defer st.applyPending()
+
+	for i := len(st.scenes) - 1; i >= 0; i-- {
+		if err := st.scenes[i].Update(i == len(st.scenes)-1); err != nil {
+			return err
+		}
+		if CoverOf(st.scenes[i]) != Overlay {
+			break
+		}
+	}
+	return nil
+}
+
+func (st *Stack) applyPending() {
+	st.updating = false
+	for _, apply := range st.pending {
+		apply()
+	}
+	st.pending = nil
+}
+
+// Draw draws the scenes from the topmost Opaque one upwards
+func (st *Stack) Draw(screen *ebiten.Image) {
+	bottom := 0
+	for i := len(st.scenes) - 1; i >= 0; i-- {
+		if CoverOf(st.scenes[i]) == Opaque {
+			bottom = i
+			break
+		}
+	}
+
+	for _, s := range st.scenes[bottom:] {
+		s.Draw(screen)
+	}
+}
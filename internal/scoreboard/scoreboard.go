@@ -0,0 +1,97 @@
+package scoreboard
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/scores"
+)
+
+// Size is how many runs the high score table lists
+const Size = 10
+
+// Table layout
+const (
+	rowHeight  = 24
+	tableScale = 1.5
+	backX      = 300
+	backY      = 620
+	backW      = 200
+	backH      = 50
+)
+
+// columns are the x offsets of the table columns from its left edge
+var columns = []float64{0, 40, 200, 310, 380, 470, 560}
+
+// Scoreboard is the high score screen opened from the map select screen
+type Scoreboard struct {
+	Runs []scores.Run // Best runs, best first
+}
+
+func NewScoreboard(runs []scores.Run) *Scoreboard {
+	return &Scoreboard{
+		Runs: runs,
+	}
+}
+
+// Update handles input for the high score screen.
+// Returns true when the back button was clicked.
+func (s *Scoreboard) Update() bool {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+
+	mx, my := ebiten.CursorPosition()
+	fx, fy := float32(mx), float32(my)
+	return fx >= backX && fx <= backX+backW && fy >= backY && fy <= backY+backH
+}
+
+func (s *Scoreboard) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		color.RGBA{20, 20, 20, 255}, false)
+
+	drawTextFunc(screen, "HIGH SCORES", 260, 50, 3.0)
+
+	DrawTable(screen, s.Runs, 110, 140, drawTextFunc)
+
+	vector.FillRect(screen, backX, backY, backW, backH, color.RGBA{0, 120, 255, 220}, false)
+	vector.StrokeRect(screen, backX, backY, backW, backH, 2, color.RGBA{255, 255, 255, 255}, false)
+	drawTextFunc(screen, "BACK", float64(backX+65), float64(backY+10), 2.5)
+}
+
+// DrawTable draws runs as a ranked table with its top-left corner at x, y
+func DrawTable(screen *ebiten.Image, runs []scores.Run, x, y float64, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	if len(runs) == 0 {
+		drawTextFunc(screen, "No runs recorded yet", x, y, tableScale)
+		return
+	}
+
+	drawRow(screen, x, y, []string{"#", "MAP", "MODE", "WAVE", "KILLS", "COINS", "TIME"}, drawTextFunc)
+	for i, run := range runs {
+		drawRow(screen, x, y+float64(i+1)*rowHeight, []string{
+			fmt.Sprintf("%d", i+1),
+			run.Map,
+			difficulty.Name(run.Preset),
+			fmt.Sprintf("%d", run.Wave),
+			fmt.Sprintf("%d", run.Kills),
+			fmt.Sprintf("%d", run.CoinsEarned),
+			formatDuration(run.Duration),
+		}, drawTextFunc)
+	}
+}
+
+func drawRow(screen *ebiten.Image, x, y float64, cells []string, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	for i, cell := range cells {
+		drawTextFunc(screen, cell, x+columns[i], y, tableScale)
+	}
+}
+
+// formatDuration formats seconds of game time as m:ss
+func formatDuration(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
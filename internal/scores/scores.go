@@ -0,0 +1,128 @@
+// Package scores keeps the history of finished runs in a JSON file in the
+// user's config directory and ranks them into a high score table.
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/difficulty"
+)
+
+// Version is the current score file format
+const Version = 1
+
+// ErrUnreadable is returned by Load when the score file is not valid JSON
+// or has another version
+var ErrUnreadable = errors.New("unreadable score file")
+
+// Run is one finished match
+type Run struct {
+	Map         string          `json:"map"`
+	Preset      difficulty.Kind `json:"preset"`     // Empty for runs recorded before presets
+	Difficulty  int             `json:"difficulty"` // Difficulty modifier reached
+	Wave        int             `json:"wave"`       // Wave the run ended on
+	Kills       int             `json:"kills"`
+	CoinsEarned int             `json:"coinsEarned"`
+	Duration    float64         `json:"duration"` // Game time in seconds
+	FinishedAt  time.Time       `json:"finishedAt"`
+}
+
+// File is the score file
+type File struct {
+	Version int   `json:"version"`
+	Runs    []Run `json:"runs"` // Oldest first
+}
+
+// Path returns where the score file is kept
+func Path() (string, error) {
+	userDir, err := config.UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, "scores.json"), nil
+}
+
+// Load reads every recorded run. A missing file is an empty history.
+func Load() ([]Run, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrUnreadable, path, err)
+	}
+	if file.Version != Version {
+		return nil, fmt.Errorf("%w %s: unsupported version %d", ErrUnreadable, path, file.Version)
+	}
+	return file.Runs, nil
+}
+
+// Record appends a run to the history and returns the updated history. An
+// unreadable score file is moved aside to scores.json.bad and a new
+// history is started, so one bad file does not stop every later run from
+// being recorded.
+func Record(run Run) ([]Run, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := Load()
+	if errors.Is(err, ErrUnreadable) {
+		if err := os.Rename(path, path+".bad"); err != nil {
+			return nil, err
+		}
+		runs = nil
+	} else if err != nil {
+		return nil, err
+	}
+	runs = append(runs, run)
+
+	data, err := json.MarshalIndent(File{Version: Version, Runs: runs}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return nil, err
+	}
+	return runs, os.Rename(tmp, path)
+}
+
+// Top returns the n best runs: furthest wave first, then most kills, then
+// the fastest. runs is not modified.
+func Top(runs []Run, n int) []Run {
+	ranked := append([]Run(nil), runs...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Wave != b.Wave {
+			return a.Wave > b.Wave
+		}
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		return a.Duration < b.Duration
+	})
+	return ranked[:min(n, len(ranked))]
+}
@@ -0,0 +1,108 @@
+package scores
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTempDir points the config directory, and with it the score file, at
+// a temporary directory
+func useTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordAppends(t *testing.T) {
+	useTempDir(t)
+
+	if _, err := Record(Run{Map: "Classic", Wave: 3}); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := Record(Run{Map: "Classic", Wave: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[1].Wave != 5 {
+		t.Fatalf("got runs %+v, want waves 3 and 5", runs)
+	}
+
+	loaded, err := Load()
+	if err != nil || len(loaded) != 2 {
+		t.Errorf("loaded %d runs, error %v; want 2", len(loaded), err)
+	}
+}
+
+func TestRecordMovesUnreadableFileAside(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "corrupt", content: `{"version": 1, "runs": [`},
+		{name: "other version", content: `{"version": 99, "runs": []}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTempDir(t)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Load(); !errors.Is(err, ErrUnreadable) {
+				t.Fatalf("got error %v, want %v", err, ErrUnreadable)
+			}
+
+			runs, err := Record(Run{Map: "Classic", Wave: 4})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs) != 1 {
+				t.Errorf("got %d runs, want a new history of 1", len(runs))
+			}
+
+			bad, err := os.ReadFile(path + ".bad")
+			if err != nil || string(bad) != tt.content {
+				t.Errorf("unreadable file not kept aside: %q, %v", bad, err)
+			}
+			if loaded, err := Load(); err != nil || len(loaded) != 1 {
+				t.Errorf("loaded %d runs, error %v; want 1", len(loaded), err)
+			}
+		})
+	}
+}
+
+func TestTop(t *testing.T) {
+	runs := []Run{
+		{Map: "a", Wave: 3, Kills: 10, Duration: 50},
+		{Map: "b", Wave: 5, Kills: 2, Duration: 90},
+		{Map: "c", Wave: 3, Kills: 12, Duration: 80},
+		{Map: "d", Wave: 3, Kills: 10, Duration: 40},
+	}
+
+	top := Top(runs, 3)
+	want := []string{"b", "c", "d"}
+	if len(top) != len(want) {
+		t.Fatalf("got %d runs, want %d", len(top), len(want))
+	}
+	for i, run := range top {
+		if run.Map != want[i] {
+			t.Errorf("rank %d: got %q, want %q", i+1, run.Map, want[i])
+		}
+	}
+	if runs[0].Map != "a" {
+		t.Error("Top reordered its input")
+	}
+}
@@ -0,0 +1,244 @@
+// Package settings holds the player's preferences: display, audio, colour
+// scheme and key bindings. They are kept in settings.json in the user's
+// config directory, applied by main at startup and again whenever the
+// settings screen changes them.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/renderer"
+)
+
+// Action is something the player can bind a key to
+type Action string
+
+const (
+	ActionMenu   Action = "menu"
+	ActionPause  Action = "pause"
+	ActionSpeed1 Action = "speed1"
+	ActionSpeed2 Action = "speed2"
+	ActionSpeed3 Action = "speed3"
+	ActionSave1  Action = "save1"
+	ActionSave2  Action = "save2"
+	ActionSave3  Action = "save3"
+
+	ActionAbility1 Action = "ability1"
+	ActionAbility2 Action = "ability2"
+	ActionAbility3 Action = "ability3"
+	ActionAbility4 Action = "ability4"
+)
+
+// Actions lists the bindable actions in the order the settings screen
+// shows them
+var Actions = []Action{
+	ActionMenu, ActionPause, ActionSpeed1, ActionSpeed2, ActionSpeed3,
+	ActionSave1, ActionSave2, ActionSave3,
+	ActionAbility1, ActionAbility2, ActionAbility3, ActionAbility4,
+}
+
+// ActionNames are the labels of the actions on the settings screen
+var ActionNames = map[Action]string{
+	ActionMenu:   "Pause menu",
+	ActionPause:  "Pause",
+	ActionSpeed1: "Speed 1",
+	ActionSpeed2: "Speed 2",
+	ActionSpeed3: "Speed 3",
+	ActionSave1:  "Save slot 1",
+	ActionSave2:  "Save slot 2",
+	ActionSave3:  "Save slot 3",
+
+	ActionAbility1: "Ability 1",
+	ActionAbility2: "Ability 2",
+	ActionAbility3: "Ability 3",
+	ActionAbility4: "Ability 4",
+}
+
+// WindowScales are the window sizes offered, as multiples of the logical
+// screen size
+var WindowScales = []float64{0.75, 1, 1.25, 1.5, 2}
+
+// TPSCaps are the update rates offered. The simulation runs on its own
+// fixed step, so they change how often input is read, not game speed.
+var TPSCaps = []int{30, 60, 120, 240}
+
+// Settings are the player's preferences
+type Settings struct {
+	Fullscreen    bool                  `json:"fullscreen"`
+	WindowScale   float64               `json:"windowScale"`
+	VSync         bool                  `json:"vsync"`
+	TPS           int                   `json:"tps"`
+	MasterVolume  float64               `json:"masterVolume"` // 0 to 1
+	MusicVolume   float64               `json:"musicVolume"`
+	EffectsVolume float64               `json:"effectsVolume"`
+	ColorScheme   string                `json:"colorScheme"` // Name in renderer.ColorSchemes
+	Keys          map[Action]ebiten.Key `json:"keys"`
+}
+
+// Default returns the settings used until the player changes them
+func Default() Settings {
+	return Settings{
+		Fullscreen:    false,
+		WindowScale:   1,
+		VSync:         true,
+		TPS:           config.Config.TPS,
+		MasterVolume:  1,
+		MusicVolume:   0.8,
+		EffectsVolume: 0.8,
+		ColorScheme:   "classic",
+		Keys: map[Action]ebiten.Key{
+			ActionMenu:   ebiten.KeyEscape,
+			ActionPause:  ebiten.KeySpace,
+			ActionSpeed1: ebiten.KeyDigit1,
+			ActionSpeed2: ebiten.KeyDigit2,
+			ActionSpeed3: ebiten.KeyDigit3,
+			ActionSave1:  ebiten.KeyF5,
+			ActionSave2:  ebiten.KeyF6,
+			ActionSave3:  ebiten.KeyF7,
+
+			ActionAbility1: ebiten.KeyQ,
+			ActionAbility2: ebiten.KeyW,
+			ActionAbility3: ebiten.KeyE,
+			ActionAbility4: ebiten.KeyR,
+		},
+	}
+}
+
+// Key returns the key bound to action
+func (s Settings) Key(action Action) ebiten.Key {
+	return s.Keys[action]
+}
+
+// Bind binds key to action. An action already using key gets the key
+// action had, so no key does two things.
+func (s *Settings) Bind(action Action, key ebiten.Key) {
+	for other, bound := range s.Keys {
+		if bound == key && other != action {
+			s.Keys[other] = s.Keys[action]
+		}
+	}
+	s.Keys[action] = key
+}
+
+// Clone returns a copy of s that can be changed without changing s
+func (s Settings) Clone() Settings {
+	keys := make(map[Action]ebiten.Key, len(s.Keys))
+	for action, key := range s.Keys {
+		keys[action] = key
+	}
+	s.Keys = keys
+	return s
+}
+
+// Path returns where the settings file is kept
+func Path() (string, error) {
+	userDir, err := config.UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, "settings.json"), nil
+}
+
+// Load reads the saved settings. A missing file gives the defaults;
+// settings missing from the file or out of range keep their default, and
+// key bindings where two actions share a key are all reset.
+func Load() (Settings, error) {
+	s := Default()
+
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	// Decoding over the defaults keeps them for whatever the file leaves out
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	return s.valid(), nil
+}
+
+// valid returns s with out of range settings set back to their default
+func (s Settings) valid() Settings {
+	defaults := Default()
+	if s.WindowScale <= 0 {
+		s.WindowScale = defaults.WindowScale
+	}
+	if s.TPS <= 0 {
+		s.TPS = defaults.TPS
+	}
+	if _, ok := renderer.ColorSchemes[s.ColorScheme]; !ok {
+		s.ColorScheme = defaults.ColorScheme
+	}
+	s.MasterVolume = clampVolume(s.MasterVolume)
+	s.MusicVolume = clampVolume(s.MusicVolume)
+	s.EffectsVolume = clampVolume(s.EffectsVolume)
+	if !s.uniqueKeys() {
+		// A file saved before an action was added may bind its default key
+		// to something else; no binding can be trusted to win
+		s.Keys = defaults.Keys
+	}
+	return s
+}
+
+func clampVolume(volume float64) float64 {
+	return min(max(volume, 0), 1)
+}
+
+// uniqueKeys reports whether every action has a key of its own
+func (s Settings) uniqueKeys() bool {
+	used := make(map[ebiten.Key]bool, len(Actions))
+	for _, action := range Actions {
+		key := s.Keys[action]
+		if used[key] {
+			return false
+		}
+		used[key] = true
+	}
+	return true
+}
+
+// Save writes the settings file
+func Save(s Settings) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Apply sets up the window, update rate and colours from the settings
+func (s Settings) Apply() {
+	ebiten.SetFullscreen(s.Fullscreen)
+	ebiten.SetWindowSize(int(float64(config.Config.Width)*s.WindowScale), int(float64(config.Config.Height)*s.WindowScale))
+	ebiten.SetVsyncEnabled(s.VSync)
+	ebiten.SetTPS(s.TPS)
+	renderer.UseColorScheme(s.ColorScheme)
+}
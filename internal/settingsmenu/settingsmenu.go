@@ -0,0 +1,237 @@
+package settingsmenu
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/nx23/final-path/internal/renderer"
+	"github.com/nx23/final-path/internal/settings"
+)
+
+// Result is what an Update did to the settings
+type Result int
+
+const (
+	ResultNone    Result = iota
+	ResultChanged        // A setting changed and should be applied
+	ResultBack           // The player is done with the screen
+)
+
+// Layout: options on the left, key bindings on the right
+const (
+	optionLabelX = 40
+	optionValueX = 230
+	keyLabelX    = 420
+	keyValueX    = 610
+	valueWidth   = 150
+	valueHeight  = 30
+	firstRowY    = 140
+	rowHeight    = 32
+	backX        = 300
+	backY        = 620
+	backW        = 200
+	backH        = 50
+)
+
+// option is one setting on the left column. step moves its value to the
+// next (dir 1) or previous (dir -1) choice.
+type option struct {
+	label string
+	value func(s settings.Settings) string
+	step  func(s *settings.Settings, dir int)
+}
+
+var options = []option{
+	{
+		label: "Fullscreen",
+		value: func(s settings.Settings) string { return onOff(s.Fullscreen) },
+		step:  func(s *settings.Settings, dir int) { s.Fullscreen = !s.Fullscreen },
+	},
+	{
+		label: "Window scale",
+		value: func(s settings.Settings) string { return fmt.Sprintf("%gx", s.WindowScale) },
+		step: func(s *settings.Settings, dir int) {
+			s.WindowScale = cycle(settings.WindowScales, s.WindowScale, dir)
+		},
+	},
+	{
+		label: "VSync",
+		value: func(s settings.Settings) string { return onOff(s.VSync) },
+		step:  func(s *settings.Settings, dir int) { s.VSync = !s.VSync },
+	},
+	{
+		label: "TPS cap",
+		value: func(s settings.Settings) string { return fmt.Sprintf("%d", s.TPS) },
+		step:  func(s *settings.Settings, dir int) { s.TPS = cycle(settings.TPSCaps, s.TPS, dir) },
+	},
+	{
+		label: "Master vol",
+		value: func(s settings.Settings) string { return percent(s.MasterVolume) },
+		step:  func(s *settings.Settings, dir int) { s.MasterVolume = stepVolume(s.MasterVolume, dir) },
+	},
+	{
+		label: "Music vol",
+		value: func(s settings.Settings) string { return percent(s.MusicVolume) },
+		step:  func(s *settings.Settings, dir int) { s.MusicVolume = stepVolume(s.MusicVolume, dir) },
+	},
+	{
+		label: "Effects vol",
+		value: func(s settings.Settings) string { return percent(s.EffectsVolume) },
+		step:  func(s *settings.Settings, dir int) { s.EffectsVolume = stepVolume(s.EffectsVolume, dir) },
+	},
+	{
+		label: "Colours",
+		value: func(s settings.Settings) string { return renderer.ColorSchemes[s.ColorScheme].Name },
+		step: func(s *settings.Settings, dir int) {
+			s.ColorScheme = cycle(renderer.ColorSchemeNames, s.ColorScheme, dir)
+		},
+	},
+}
+
+// SettingsMenu edits the settings: click an option to move it to the next
+// choice (right click for the previous one), click a key binding and
+// press the new key
+type SettingsMenu struct {
+	Settings  settings.Settings
+	rebinding settings.Action // Action waiting for a key press, "" if none
+}
+
+func NewSettingsMenu(s settings.Settings) *SettingsMenu {
+	return &SettingsMenu{
+		Settings: s,
+	}
+}
+
+// Update handles input for the settings screen
+func (m *SettingsMenu) Update() Result {
+	if m.rebinding != "" {
+		if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+			m.Settings.Bind(m.rebinding, keys[0])
+			m.rebinding = ""
+			return ResultChanged
+		}
+		// Any click cancels rebinding
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+			m.rebinding = ""
+		}
+		return ResultNone
+	}
+
+	if inpututil.IsKeyJustPressed(m.Settings.Key(settings.ActionMenu)) {
+		return ResultBack
+	}
+
+	left := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	right := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		return ResultNone
+	}
+
+	mx, my := ebiten.CursorPosition()
+	dir := 1
+	if right {
+		dir = -1
+	}
+
+	for i, opt := range options {
+		if isInside(mx, my, optionValueX, rowY(i), valueWidth, valueHeight) {
+			opt.step(&m.Settings, dir)
+			return ResultChanged
+		}
+	}
+
+	if left {
+		for i, action := range settings.Actions {
+			if isInside(mx, my, keyValueX, rowY(i), valueWidth, valueHeight) {
+				m.rebinding = action
+				return ResultNone
+			}
+		}
+
+		if isInside(mx, my, backX, backY, backW, backH) {
+			return ResultBack
+		}
+	}
+
+	return ResultNone
+}
+
+func (m *SettingsMenu) Draw(screen *ebiten.Image, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
+		color.RGBA{20, 20, 20, 255}, false)
+
+	drawTextFunc(screen, "SETTINGS", 316, 30, 3.5)
+	drawTextFunc(screen, "OPTIONS", optionLabelX, 100, 2.0)
+	drawTextFunc(screen, "KEYS", keyLabelX, 100, 2.0)
+
+	for i, opt := range options {
+		y := rowY(i)
+		drawTextFunc(screen, opt.label, optionLabelX, float64(y+5), 1.8)
+		drawValue(screen, optionValueX, y, opt.value(m.Settings), color.RGBA{0, 120, 255, 220}, drawTextFunc)
+	}
+
+	for i, action := range settings.Actions {
+		y := rowY(i)
+		drawTextFunc(screen, settings.ActionNames[action], keyLabelX, float64(y+5), 1.8)
+		if action == m.rebinding {
+			drawValue(screen, keyValueX, y, "Press a key", color.RGBA{200, 120, 0, 220}, drawTextFunc)
+		} else {
+			drawValue(screen, keyValueX, y, m.Settings.Key(action).String(), color.RGBA{0, 120, 255, 220}, drawTextFunc)
+		}
+	}
+
+	drawTextFunc(screen, "Click to change, right-click to go back a choice", optionLabelX, 535, 1.6)
+	drawTextFunc(screen, "Shift + a save key loads the slot instead", optionLabelX, 560, 1.6)
+	drawTextFunc(screen, "Volumes apply once the game has sound", optionLabelX, 585, 1.6)
+
+	vector.FillRect(screen, backX, backY, backW, backH, color.RGBA{0, 120, 255, 220}, false)
+	vector.StrokeRect(screen, backX, backY, backW, backH, 2, color.RGBA{255, 255, 255, 255}, false)
+	drawTextFunc(screen, "BACK", float64(backX+65), float64(backY+10), 2.5)
+}
+
+func drawValue(screen *ebiten.Image, x, y float32, value string, fill color.RGBA, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
+	vector.FillRect(screen, x, y, valueWidth, valueHeight, fill, false)
+	vector.StrokeRect(screen, x, y, valueWidth, valueHeight, 2, color.RGBA{255, 255, 255, 255}, false)
+	drawTextFunc(screen, value, float64(x+8), float64(y+5), 1.6)
+}
+
+// rowY returns the top of the i-th row of either column
+func rowY(i int) float32 {
+	return float32(firstRowY + i*rowHeight)
+}
+
+// cycle returns the choice dir steps away from current, wrapping around.
+// A current value that is not a choice moves to the first one.
+func cycle[T comparable](choices []T, current T, dir int) T {
+	i := slices.Index(choices, current)
+	if i < 0 {
+		return choices[0]
+	}
+	return choices[(i+dir+len(choices))%len(choices)]
+}
+
+// stepVolume moves a volume by 10%, wrapping between 0% and 100%
+func stepVolume(volume float64, dir int) float64 {
+	tenths := (int(volume*10+0.5) + dir + 11) % 11
+	return float64(tenths) / 10
+}
+
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+func percent(volume float64) string {
+	return fmt.Sprintf("%d%%", int(volume*100+0.5))
+}
+
+func isInside(mx, my int, x, y, width, height float32) bool {
+	fx, fy := float32(mx), float32(my)
+	return fx >= x && fx <= x+width && fy >= y && fy <= y+height
+}
@@ -0,0 +1,159 @@
+package shop
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/nx23/final-path/internal/utils"
+)
+
+//go:embed catalog.json
+var builtinCatalog []byte
+
+// Catalog is the list of items sold in the shop, in display order
+type Catalog struct {
+	Items []ItemDef `json:"items" yaml:"items"`
+}
+
+// ItemDef describes an item as written in a catalog file. The price of
+// the next purchase is Cost * CostScale^bought + CostStep * bought.
+type ItemDef struct {
+	ID           string        `json:"id" yaml:"id"` // Unique, used by commands, saves and requirements
+	Name         string        `json:"name" yaml:"name"`
+	Description  string        `json:"description" yaml:"description"`
+	Cost         int           `json:"cost" yaml:"cost"`                 // Price of the first purchase
+	CostStep     int           `json:"costStep" yaml:"costStep"`         // Added to the price after every purchase
+	CostScale    float32       `json:"costScale" yaml:"costScale"`       // Multiplies the price after every purchase, 0 means 1
+	MaxPurchases int           `json:"maxPurchases" yaml:"maxPurchases"` // 0 for no limit
+	Requires     []Requirement `json:"requires" yaml:"requires"`         // All must be met before the item can be bought
+	Effect       EffectDef     `json:"effect" yaml:"effect"`
+}
+
+// Requirement unlocks an item once another item was bought Count times
+type Requirement struct {
+	Item  string `json:"item" yaml:"item"`
+	Count int    `json:"count" yaml:"count"` // 0 means 1
+}
+
+// EffectDef names the effect of an item and its strength
+type EffectDef struct {
+	Kind   EffectKind `json:"kind" yaml:"kind"`
+	Amount float32    `json:"amount" yaml:"amount"`
+}
+
+// Default is the catalog new shops are stocked from. main replaces it
+// when a catalog file is given.
+var Default = mustParse(builtinCatalog)
+
+func mustParse(data []byte) Catalog {
+	catalog, err := parse("catalog.json", data)
+	if err != nil {
+		panic(fmt.Sprintf("built-in shop catalog: %v", err))
+	}
+	return catalog
+}
+
+// Load reads a shop catalog file. Files ending in .yaml/.yml are read as
+// YAML, anything else as JSON.
+//
+//	{
+//	  "items": [
+//	    {"id": "towerSlot", "name": "Buy Tower Slot", "cost": 100, "costStep": 100,
+//	     "effect": {"kind": "towerSlots", "amount": 1}},
+//	    {"id": "repairBase", "name": "Repair Base +2", "cost": 60, "costScale": 1.5,
+//	     "maxPurchases": 3, "requires": [{"item": "towerSlot", "count": 1}],
+//	     "effect": {"kind": "lives", "amount": 2}}
+//	  ]
+//	}
+func Load(path string) (Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	catalog, err := parse(path, data)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// parse decodes and validates a catalog read from path
+func parse(path string, data []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := utils.Decode(path, data, &catalog); err != nil {
+		return Catalog{}, err
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return Catalog{}, err
+	}
+	return catalog, nil
+}
+
+// Validate checks IDs are unique, effects are registered, prices and
+// caps are not negative and requirements refer to other items
+func (c Catalog) Validate() error {
+	if len(c.Items) == 0 {
+		return errors.New("catalog has no items")
+	}
+
+	ids := map[string]bool{}
+	for _, item := range c.Items {
+		if item.ID == "" {
+			return fmt.Errorf("item %q has no id", item.Name)
+		}
+		if ids[item.ID] {
+			return fmt.Errorf("item id %q used twice", item.ID)
+		}
+		ids[item.ID] = true
+	}
+
+	for _, item := range c.Items {
+		if err := item.validate(ids); err != nil {
+			return fmt.Errorf("item %q: %w", item.ID, err)
+		}
+	}
+	return nil
+}
+
+func (item ItemDef) validate(ids map[string]bool) error {
+	if item.Cost < 0 || item.CostStep < 0 || item.CostScale < 0 {
+		return errors.New("cost, costStep and costScale cannot be negative")
+	}
+	if item.MaxPurchases < 0 {
+		return errors.New("maxPurchases cannot be negative")
+	}
+	if _, ok := EffectTypes[item.Effect.Kind]; !ok {
+		return fmt.Errorf("unknown effect %q", item.Effect.Kind)
+	}
+	for _, req := range item.Requires {
+		if req.Item == item.ID {
+			return errors.New("an item cannot require itself")
+		}
+		if !ids[req.Item] {
+			return fmt.Errorf("requires unknown item %q", req.Item)
+		}
+		if req.Count < 0 {
+			return fmt.Errorf("requirement on %q cannot have a negative count", req.Item)
+		}
+	}
+	return nil
+}
+
+// CostAfter returns the price of the item once it was bought n times
+func (item ItemDef) CostAfter(n int) int {
+	scale := item.CostScale
+	if scale == 0 {
+		scale = 1
+	}
+	return int(math.Round(float64(item.Cost)*math.Pow(float64(scale), float64(n)))) + item.CostStep*n
+}
+
+// count returns how many purchases the requirement asks for
+func (req Requirement) count() int {
+	return max(req.Count, 1)
+}
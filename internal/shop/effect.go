@@ -0,0 +1,49 @@
+package shop
+
+// Match is the part of a match that item effects act on. The simulation
+// implements it.
+type Match interface {
+	AddTowerSlots(n int)
+	AddTowerDamage(n int)
+	AddTowerFireRate(rate float32)
+	AddLives(n int)
+}
+
+// Effect is what buying an item does to the match
+type Effect interface {
+	Apply(m Match)
+}
+
+// EffectFunc adapts a function to the Effect interface
+type EffectFunc func(m Match)
+
+func (f EffectFunc) Apply(m Match) {
+	f(m)
+}
+
+// EffectKind names an effect in catalog files
+type EffectKind string
+
+const (
+	EffectTowerSlots    EffectKind = "towerSlots"    // Amount extra towers can be placed
+	EffectTowerDamage   EffectKind = "towerDamage"   // Amount damage added to every tower
+	EffectTowerFireRate EffectKind = "towerFireRate" // Amount added to the fire rate multiplier of every tower
+	EffectLives         EffectKind = "lives"         // Amount lives restored
+)
+
+// EffectTypes is the registry of every effect an item can have. Each
+// entry builds the effect for the amount given in the catalog.
+var EffectTypes = map[EffectKind]func(amount float32) Effect{
+	EffectTowerSlots: func(amount float32) Effect {
+		return EffectFunc(func(m Match) { m.AddTowerSlots(int(amount)) })
+	},
+	EffectTowerDamage: func(amount float32) Effect {
+		return EffectFunc(func(m Match) { m.AddTowerDamage(int(amount)) })
+	},
+	EffectTowerFireRate: func(amount float32) Effect {
+		return EffectFunc(func(m Match) { m.AddTowerFireRate(amount) })
+	},
+	EffectLives: func(amount float32) Effect {
+		return EffectFunc(func(m Match) { m.AddLives(int(amount)) })
+	},
+}
@@ -1,172 +1,146 @@
 package shop
 
-import (
-	"fmt"
-	"image/color"
+import "errors"
+
+// Errors returned by PurchaseItem. The messages are shown to the player
+// as-is.
+var (
+	ErrUnknownItem    = errors.New("Unknown shop item!")
+	ErrItemLocked     = errors.New("This item is not unlocked yet!")
+	ErrItemSoldOut    = errors.New("This item is sold out!")
+	ErrNotEnoughCoins = errors.New("Not enough coins to buy this item!")
+)
 
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+// Item layout, relative to the shop
+const (
+	firstItemY   = 100
+	itemStep     = 60
+	itemX        = 20
+	itemWidth    = 360
+	itemHeight   = 50
+	panelBottom  = 40 // Space below the last item
+	visibleItems = 5  // Items shown at once; longer catalogs scroll
 )
 
 type Shop struct {
-	Open   bool
 	X      float32
 	Y      float32
 	Width  float32
 	Height float32
 	Items  []ShopItem
+	First  int // Index of the first item shown
 }
 
+// ShopItem is a catalog item and how often it was bought this match
 type ShopItem struct {
-	ID          int
-	Name        string
-	Cost        int
-	Description string
-	Y           float32 // Y position relative to shop
+	ItemDef
+	Bought int
+	Y      float32 // Y position relative to shop, meaningful while visible
 }
 
+// NewShop returns a shop stocked from the Default catalog
 func NewShop() *Shop {
-	return &Shop{
-		Open:   false,
-		X:      200,
-		Y:      200,
-		Width:  400,
-		Height: 320,
-		Items: []ShopItem{
-			{ID: 0, Name: "Buy Tower Slot", Cost: 100, Description: "Add +1 tower slot", Y: 100},
-			{ID: 1, Name: "Tower Damage +5", Cost: 25, Description: "Increase all tower damage by +5", Y: 160},
-			{ID: 2, Name: "Fire Rate +10%", Cost: 20, Description: "Increase all tower fire rate by 10%", Y: 220},
-		},
+	s := &Shop{
+		X:     200,
+		Y:     200,
+		Width: 400,
 	}
-}
-
-func (s *Shop) Draw(screen *ebiten.Image, coins int, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
-	if !s.Open {
-		return
+	for _, def := range Default.Items {
+		s.Items = append(s.Items, ShopItem{ItemDef: def})
 	}
+	s.Height = float32(firstItemY + min(len(s.Items), visibleItems)*itemStep + panelBottom)
+	s.layout()
+	return s
+}
 
-	// Semi-transparent overlay
-	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()),
-		color.RGBA{0, 0, 0, 180}, false)
-
-	// Shop panel
-	vector.FillRect(screen, s.X, s.Y, s.Width, s.Height, color.RGBA{40, 40, 40, 255}, false)
-	vector.StrokeRect(screen, s.X, s.Y, s.Width, s.Height, 3, color.RGBA{255, 165, 0, 255}, false)
+// Visible returns the items currently shown
+func (s *Shop) Visible() []ShopItem {
+	return s.Items[s.First:min(s.First+visibleItems, len(s.Items))]
+}
 
-	// Title
-	drawTextFunc(screen, "SHOP", 360, 215, 3.0)
+// CanScroll reports whether some items are out of view
+func (s *Shop) CanScroll() bool {
+	return len(s.Items) > visibleItems
+}
 
-	// Coins display
-	coinsText := fmt.Sprintf("Coins: %d", coins)
-	drawTextFunc(screen, coinsText, 340, 260, 2.0)
+// Scroll moves the list by dir items, stopping at either end
+func (s *Shop) Scroll(dir int) {
+	s.First = min(max(s.First+dir, 0), max(len(s.Items)-visibleItems, 0))
+	s.layout()
+}
 
-	// Draw shop items
-	for _, item := range s.Items {
-		s.drawItem(screen, item, coins, drawTextFunc)
+// layout places the items from the first one shown
+func (s *Shop) layout() {
+	for i := range s.Items {
+		s.Items[i].Y = float32(firstItemY + (i-s.First)*itemStep)
 	}
-
-	// Close instruction
-	drawTextFunc(screen, "Right-click to close", 320, 600, 1.5)
 }
 
-// drawItem renders a single shop item
-func (s *Shop) drawItem(screen *ebiten.Image, item ShopItem, coins int, drawTextFunc func(*ebiten.Image, string, float64, float64, float64)) {
-	itemX := s.X + 20
-	itemY := s.Y + item.Y
-	itemWidth := float32(360)
-	itemHeight := float32(50)
-
-	canAfford := coins >= item.Cost
-
-	// Background color based on affordability
-	var bgColor color.RGBA
-	if canAfford {
-		bgColor = color.RGBA{0, 100, 0, 200} // Green if affordable
-	} else {
-		bgColor = color.RGBA{100, 0, 0, 200} // Red if not
-	}
+// Price returns the price of the next purchase
+func (item ShopItem) Price() int {
+	return item.CostAfter(item.Bought)
+}
 
-	vector.FillRect(screen, itemX, itemY, itemWidth, itemHeight, bgColor, false)
-	vector.StrokeRect(screen, itemX, itemY, itemWidth, itemHeight, 2, color.RGBA{255, 255, 255, 255}, false)
+// SoldOut reports whether the item reached its purchase cap
+func (item ShopItem) SoldOut() bool {
+	return item.MaxPurchases > 0 && item.Bought >= item.MaxPurchases
+}
 
-	// Item text
-	itemText := fmt.Sprintf("%s - %d coins", item.Name, item.Cost)
-	drawTextFunc(screen, itemText, float64(itemX+10), float64(itemY+15), 1.8)
+// Item returns the item with the given ID
+func (s *Shop) Item(id string) (*ShopItem, bool) {
+	for i := range s.Items {
+		if s.Items[i].ID == id {
+			return &s.Items[i], true
+		}
+	}
+	return nil, false
 }
 
-// HandleClick processes click events on shop items
-// Returns the item ID if a purchase was made, or 0 if no purchase
-func (s *Shop) HandleClick(mx, my int, coins int) (itemID int, purchased bool) {
-	if !s.Open {
-		return 0, false
+// Missing returns the first requirement of item not met yet
+func (s *Shop) Missing(item ShopItem) (Requirement, bool) {
+	for _, req := range item.Requires {
+		if other, ok := s.Item(req.Item); !ok || other.Bought < req.count() {
+			return req, true
+		}
 	}
+	return Requirement{}, false
+}
 
-	for _, item := range s.Items {
-		itemX := int(s.X + 20)
-		itemY := int(s.Y + item.Y)
-		itemWidth := 360
-		itemHeight := 50
+// HandleClick returns the ID of the shown item under the cursor
+func (s *Shop) HandleClick(mx, my int) (itemID string, clicked bool) {
+	for _, item := range s.Visible() {
+		x := int(s.X + itemX)
+		y := int(s.Y + item.Y)
 
 		// Check if click is within item bounds
-		if mx >= itemX && mx <= itemX+itemWidth && my >= itemY && my <= itemY+itemHeight {
-			if coins >= item.Cost {
-				return item.ID, true
-			}
-			return item.ID, false // Clicked but can't afford
+		if mx >= x && mx <= x+itemWidth && my >= y && my <= y+itemHeight {
+			return item.ID, true
 		}
 	}
 
-	return 0, false
-}
-
-// Toggle opens or closes the shop
-func (s *Shop) Toggle() {
-	s.Open = !s.Open
-}
-
-// Close closes the shop
-func (s *Shop) Close() {
-	s.Open = false
+	return "", false
 }
 
-func (s *Shop) PurchaseItem(itemID, coins, towerLimit, towerDamageBoost int, towerFireRateBoost float32) (newCoins, newTowerLimit, newDamageBoost int, newFireRateBoost float32, success bool) {
-	// Find the item
-	var item *ShopItem
-	for i := range s.Items {
-		if s.Items[i].ID == itemID {
-			item = &s.Items[i]
-			break
-		}
+// PurchaseItem buys the item with the given ID for a player holding coins
+// and applies its effect to m. It returns the price to deduct.
+func (s *Shop) PurchaseItem(itemID string, coins int, m Match) (int, error) {
+	item, ok := s.Item(itemID)
+	if !ok {
+		return 0, ErrUnknownItem
 	}
-
-	if item == nil || coins < item.Cost {
-		return coins, towerLimit, towerDamageBoost, towerFireRateBoost, false
+	if _, missing := s.Missing(*item); missing {
+		return 0, ErrItemLocked
 	}
-
-	// Deduct cost
-	newCoins = coins - item.Cost
-	newTowerLimit = towerLimit
-	newDamageBoost = towerDamageBoost
-	newFireRateBoost = towerFireRateBoost
-
-	// Apply effect based on item ID
-	switch itemID {
-	case 0: // Buy Tower Slot
-		newTowerLimit++
-		s.UpdateItemCosts(0, item.Cost+100)
-	case 1: // Tower Damage Upgrade
-		newDamageBoost += 5
-		s.UpdateItemCosts(1, item.Cost+35)
-	case 2: // Fire Rate Upgrade
-		newFireRateBoost += 0.1
-		s.UpdateItemCosts(2, item.Cost+20)
-	default:
-		return coins, towerLimit, towerDamageBoost, towerFireRateBoost, false
+	if item.SoldOut() {
+		return 0, ErrItemSoldOut
 	}
 
-	return newCoins, newTowerLimit, newDamageBoost, newFireRateBoost, true
-}
+	cost := item.Price()
+	if coins < cost {
+		return 0, ErrNotEnoughCoins
+	}
 
-func (s *Shop) UpdateItemCosts(itemIndex int, newCost int) {
-	s.Items[itemIndex].Cost = newCost
+	EffectTypes[item.Effect.Kind](item.Effect.Amount).Apply(m)
+	item.Bought++
+	return cost, nil
 }
@@ -0,0 +1,147 @@
+package shop
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeMatch records what item effects did to it
+type fakeMatch struct {
+	slots, damage, lives int
+	fireRate             float32
+}
+
+func (m *fakeMatch) AddTowerSlots(n int)           { m.slots += n }
+func (m *fakeMatch) AddTowerDamage(n int)          { m.damage += n }
+func (m *fakeMatch) AddTowerFireRate(rate float32) { m.fireRate += rate }
+func (m *fakeMatch) AddLives(n int)                { m.lives += n }
+
+// testCatalog has an item with a growing price, a capped one with a
+// scaled price and one locked behind two purchases of the first
+var testCatalog = Catalog{Items: []ItemDef{
+	{ID: "slot", Cost: 100, CostStep: 50, Effect: EffectDef{Kind: EffectTowerSlots, Amount: 1}},
+	{ID: "rate", Cost: 20, CostScale: 2, MaxPurchases: 2, Effect: EffectDef{Kind: EffectTowerFireRate, Amount: 0.5}},
+	{ID: "repair", Cost: 60, Requires: []Requirement{{Item: "slot", Count: 2}}, Effect: EffectDef{Kind: EffectLives, Amount: 2}},
+}}
+
+// newTestShop returns a shop stocked from testCatalog
+func newTestShop() *Shop {
+	s := &Shop{}
+	for _, def := range testCatalog.Items {
+		s.Items = append(s.Items, ShopItem{ItemDef: def})
+	}
+	return s
+}
+
+func TestPurchaseEscalatingCost(t *testing.T) {
+	tests := []struct {
+		id    string
+		costs []int
+	}{
+		{id: "slot", costs: []int{100, 150, 200, 250}},
+		{id: "rate", costs: []int{20, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			s, m := newTestShop(), &fakeMatch{}
+			for i, want := range tt.costs {
+				cost, err := s.PurchaseItem(tt.id, 1000, m)
+				if err != nil {
+					t.Fatalf("purchase %d: %v", i+1, err)
+				}
+				if cost != want {
+					t.Errorf("purchase %d: got cost %d, want %d", i+1, cost, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPurchaseAppliesEffect(t *testing.T) {
+	s, m := newTestShop(), &fakeMatch{}
+	for _, id := range []string{"slot", "slot", "rate", "repair"} {
+		if _, err := s.PurchaseItem(id, 1000, m); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	if m.slots != 2 || m.fireRate != 0.5 || m.lives != 2 || m.damage != 0 {
+		t.Errorf("got %+v, want 2 slots, fire rate 0.5, 2 lives", *m)
+	}
+}
+
+func TestPurchaseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		bought map[string]int // Purchases made before the attempt
+		id     string
+		coins  int
+		err    error
+	}{
+		{name: "unknown item", id: "jetpack", coins: 1000, err: ErrUnknownItem},
+		{name: "locked", id: "repair", coins: 1000, err: ErrItemLocked},
+		{name: "partly unlocked", bought: map[string]int{"slot": 1}, id: "repair", coins: 1000, err: ErrItemLocked},
+		{name: "sold out", bought: map[string]int{"rate": 2}, id: "rate", coins: 1000, err: ErrItemSoldOut},
+		{name: "not enough coins", id: "slot", coins: 99, err: ErrNotEnoughCoins},
+		{name: "not enough for the raised price", bought: map[string]int{"slot": 1}, id: "slot", coins: 100, err: ErrNotEnoughCoins},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTestShop(), &fakeMatch{}
+			for id, n := range tt.bought {
+				item, _ := s.Item(id)
+				item.Bought = n
+			}
+
+			cost, err := s.PurchaseItem(tt.id, tt.coins, m)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if cost != 0 || *m != (fakeMatch{}) {
+				t.Errorf("refused purchase cost %d and changed the match to %+v", cost, *m)
+			}
+			if item, ok := s.Item(tt.id); ok && item.Bought != tt.bought[tt.id] {
+				t.Errorf("refused purchase counted: bought %d times", item.Bought)
+			}
+		})
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		item ItemDef
+		err  string
+	}{
+		{name: "unknown effect", item: ItemDef{ID: "x", Effect: EffectDef{Kind: "teleport"}}, err: `item "x": unknown effect "teleport"`},
+		{
+			name: "missing requirement",
+			item: ItemDef{ID: "x", Requires: []Requirement{{Item: "nothing"}}, Effect: EffectDef{Kind: EffectLives}},
+			err:  `item "x": requires unknown item "nothing"`,
+		},
+		{name: "duplicate id", item: ItemDef{ID: "slot", Effect: EffectDef{Kind: EffectLives}}, err: `item id "slot" used twice`},
+		{name: "negative cost", item: ItemDef{ID: "x", Cost: -1, Effect: EffectDef{Kind: EffectLives}}, err: "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := Catalog{Items: append(append([]ItemDef(nil), testCatalog.Items...), tt.item)}
+			if err := catalog.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+
+	if err := testCatalog.Validate(); err != nil {
+		t.Errorf("valid catalog rejected: %v", err)
+	}
+}
+
+func TestParseRejectsInvalidCatalog(t *testing.T) {
+	data := []byte(`{"items": [{"id": "x", "cost": 10, "effect": {"kind": "teleport", "amount": 1}}]}`)
+	if _, err := parse("catalog.json", data); err == nil || !strings.Contains(err.Error(), `unknown effect "teleport"`) {
+		t.Errorf("got error %v, want an unknown effect", err)
+	}
+}
@@ -0,0 +1,184 @@
+package sim
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nx23/final-path/internal/entity"
+)
+
+// Errors returned by Step when an ability cannot be used.
+// The messages are shown to the player as-is.
+var (
+	ErrUnknownAbility = errors.New("Unknown ability!")
+	ErrAbilityCharges = errors.New("No charges left for this ability!")
+	ErrAbilityCooling = errors.New("Ability is still cooling down!")
+	ErrNoWave         = errors.New("No wave in progress!")
+	ErrGoldRushActive = errors.New("Gold rush is already active!")
+	ErrLivesFull      = errors.New("Lives are already full!")
+)
+
+// AbilityKind identifies an ability in the AbilityTypes registry
+type AbilityKind string
+
+const (
+	AbilityAirstrike AbilityKind = "airstrike"
+	AbilityFreeze    AbilityKind = "freeze"
+	AbilityGoldRush  AbilityKind = "goldRush"
+	AbilityRepair    AbilityKind = "repair"
+)
+
+// AbilityKinds lists every ability in HUD order, which is also the order
+// of the ability key bindings
+var AbilityKinds = []AbilityKind{AbilityAirstrike, AbilityFreeze, AbilityGoldRush, AbilityRepair}
+
+// AbilityType holds what an ability does and how often it can be used.
+// The meaning of Amount depends on the kind; see AbilityTypes.
+type AbilityType struct {
+	Name     string
+	Aimed    bool    // Used at a point picked with the mouse
+	Radius   float32 // Aimed only: distance from the point affected
+	Amount   float32
+	Cooldown float32 // Seconds of game time before it can be used again
+	Charges  int     // Uses per match, 0 for unlimited
+
+	Check func(s *Simulation) error                        // Refuses the use before it costs anything, nil to always allow
+	Use   func(s *Simulation, a AbilityType, x, y float32) // Applies a at (x, y); the point is ignored when not aimed
+}
+
+// AbilityTypes is the registry of every ability
+var AbilityTypes = map[AbilityKind]AbilityType{
+	AbilityAirstrike: {
+		Name:     "Airstrike",
+		Aimed:    true,
+		Radius:   80,
+		Amount:   60, // Fire damage dealt to every enemy in the radius
+		Cooldown: 20,
+		Check:    requireWave,
+		Use: func(s *Simulation, a AbilityType, x, y float32) {
+			for _, enemy := range s.Enemies {
+				dx, dy := enemy.PositionX-x, enemy.PositionY-y
+				if enemy.IsAlive() && dx*dx+dy*dy <= a.Radius*a.Radius {
+					enemy.TakeDamage(entity.Damage{Amount: a.Amount, Type: entity.DamageFire})
+				}
+			}
+		},
+	},
+	AbilityFreeze: {
+		Name:     "Freeze",
+		Amount:   3, // Seconds every enemy on the map is stunned
+		Cooldown: 40,
+		Check:    requireWave,
+		Use: func(s *Simulation, a AbilityType, x, y float32) {
+			for _, enemy := range s.Enemies {
+				enemy.ApplyEffect(entity.Effect{Kind: entity.EffectStun, Duration: a.Amount})
+			}
+		},
+	},
+	AbilityGoldRush: {
+		Name:    "Gold Rush",
+		Amount:  2, // Bounty multiplier until the wave ends
+		Charges: 2,
+		Check: func(s *Simulation) error {
+			if s.GoldRush > 0 {
+				return ErrGoldRushActive
+			}
+			return requireWave(s)
+		},
+		Use: func(s *Simulation, a AbilityType, x, y float32) {
+			s.GoldRush = a.Amount
+		},
+	},
+	AbilityRepair: {
+		Name:    "Repair",
+		Amount:  1, // Lives restored, up to the lives the match started with
+		Charges: 3,
+		Check: func(s *Simulation) error {
+			if s.Lives >= s.StartLives {
+				return ErrLivesFull
+			}
+			return nil
+		},
+		Use: func(s *Simulation, a AbilityType, x, y float32) {
+			s.Lives = min(s.Lives+int(a.Amount), s.StartLives)
+		},
+	},
+}
+
+// LookupAbilityType returns the registered ability for kind
+func LookupAbilityType(kind AbilityKind) (AbilityType, bool) {
+	abilityType, ok := AbilityTypes[kind]
+	return abilityType, ok
+}
+
+func requireWave(s *Simulation) error {
+	if !s.WaveActive {
+		return ErrNoWave
+	}
+	return nil
+}
+
+// AbilityState is the cooldown and the charges left of one ability
+type AbilityState struct {
+	ReadyAt int `json:"readyAt"` // Tick from which it can be used again
+	Charges int `json:"charges"` // Uses left, unused for abilities without charges
+}
+
+// newAbilities returns every ability ready with its full charges
+func newAbilities() map[AbilityKind]AbilityState {
+	abilities := make(map[AbilityKind]AbilityState, len(AbilityTypes))
+	for kind, abilityType := range AbilityTypes {
+		abilities[kind] = AbilityState{Charges: abilityType.Charges}
+	}
+	return abilities
+}
+
+// CooldownLeft returns the seconds of game time before kind can be used
+// again, 0 when it is ready
+func (s *Simulation) CooldownLeft(kind AbilityKind) float64 {
+	return s.seconds(max(s.Abilities[kind].ReadyAt-s.Tick, 0))
+}
+
+// ChargesLeft returns the uses left of kind, -1 when it has no charges
+func (s *Simulation) ChargesLeft(kind AbilityKind) int {
+	if AbilityTypes[kind].Charges == 0 {
+		return -1
+	}
+	return s.Abilities[kind].Charges
+}
+
+// useAbility uses kind at (x, y) and starts its cooldown
+func (s *Simulation) useAbility(kind AbilityKind, x, y float32) error {
+	abilityType, ok := LookupAbilityType(kind)
+	if !ok {
+		return ErrUnknownAbility
+	}
+
+	state := s.Abilities[kind]
+	if abilityType.Charges > 0 && state.Charges <= 0 {
+		return ErrAbilityCharges
+	}
+	if s.Tick < state.ReadyAt {
+		return ErrAbilityCooling
+	}
+	if abilityType.Check != nil {
+		if err := abilityType.Check(s); err != nil {
+			return err
+		}
+	}
+
+	abilityType.Use(s, abilityType, x, y)
+
+	state.ReadyAt = s.Tick + int(math.Ceil(float64(abilityType.Cooldown)*float64(s.TickRate)))
+	if abilityType.Charges > 0 {
+		state.Charges--
+	}
+	s.Abilities[kind] = state
+
+	if abilityType.Aimed {
+		s.logf("%s used at (%.1f, %.1f)!", abilityType.Name, x, y)
+	} else {
+		s.logf("%s used!", abilityType.Name)
+	}
+	return nil
+}
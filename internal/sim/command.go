@@ -0,0 +1,65 @@
+package sim
+
+import "github.com/nx23/final-path/internal/entity"
+
+// CommandKind identifies which action a Command asks the simulation to perform
+type CommandKind int
+
+const (
+	CommandPlaceTower CommandKind = iota
+	CommandSellTower
+	CommandUpgradeTower
+	CommandSetTargeting
+	CommandBuyItem
+	CommandStartWave
+	CommandUseAbility
+)
+
+// Command is an explicit player action fed to Simulation.Step.
+// Only the fields relevant to Kind are read.
+type Command struct {
+	Kind    CommandKind
+	X       float32           // Tower center X (place), any point over the tower (sell/upgrade) or the aimed point (ability)
+	Y       float32           // Tower center Y (place), any point over the tower (sell/upgrade) or the aimed point (ability)
+	ItemID  string            // Shop item (buy)
+	Tower   entity.TowerKind  // Tower type (place)
+	Target  entity.TargetMode // Targeting mode (set targeting)
+	Ability AbilityKind       // Ability (use ability)
+}
+
+// PlaceTower builds a tower of the given kind centered at (x, y)
+func PlaceTower(x, y float32, kind entity.TowerKind) Command {
+	return Command{Kind: CommandPlaceTower, X: x, Y: y, Tower: kind}
+}
+
+// SellTower removes the tower covering (x, y) and refunds part of what
+// was invested in it
+func SellTower(x, y float32) Command {
+	return Command{Kind: CommandSellTower, X: x, Y: y}
+}
+
+// UpgradeTower buys the next upgrade tier of the tower covering (x, y)
+func UpgradeTower(x, y float32) Command {
+	return Command{Kind: CommandUpgradeTower, X: x, Y: y}
+}
+
+// SetTargeting changes the targeting mode of the tower covering (x, y)
+func SetTargeting(x, y float32, mode entity.TargetMode) Command {
+	return Command{Kind: CommandSetTargeting, X: x, Y: y, Target: mode}
+}
+
+// BuyItem purchases a shop item by catalog ID
+func BuyItem(itemID string) Command {
+	return Command{Kind: CommandBuyItem, ItemID: itemID}
+}
+
+// StartWave starts the next wave of enemies
+func StartWave() Command {
+	return Command{Kind: CommandStartWave}
+}
+
+// UseAbility uses an ability at (x, y). The point only matters for aimed
+// abilities.
+func UseAbility(kind AbilityKind, x, y float32) Command {
+	return Command{Kind: CommandUseAbility, Ability: kind, X: x, Y: y}
+}
@@ -0,0 +1,504 @@
+// Package sim holds the headless game simulation.
+// It has no dependency on Ebiten input or drawing, so a match can be
+// stepped from tests and tools without opening a window.
+package sim
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/entity"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/shop"
+	"github.com/nx23/final-path/internal/spatial"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// Errors returned by Step when a command cannot be applied.
+// The messages are shown to the player as-is.
+var (
+	ErrHUDArea        = errors.New("Cannot place tower in HUD area!")
+	ErrTowerOverlap   = errors.New("Cannot place tower on another tower!")
+	ErrTowerLimit     = errors.New("Tower limit reached! Buy more slots in the shop.")
+	ErrNotEnoughCoins = errors.New("Not enough coins to place tower!")
+	ErrOnPath         = errors.New("Cannot place tower on path!")
+	ErrNotBuildable   = errors.New("Cannot build outside the buildable area!")
+	ErrUnknownTower   = errors.New("Unknown tower type!")
+	ErrNoTower        = errors.New("No tower here!")
+	ErrMaxLevel       = errors.New("Tower is already at max level!")
+	ErrUpgradeCoins   = errors.New("Not enough coins to upgrade tower!")
+	ErrWaveActive     = errors.New("Wave already in progress!")
+	ErrUnknownCommand = errors.New("Unknown command")
+)
+
+// gridCellSize is the cell size of the enemy spatial index, close to the
+// usual tower range so a targeting query only visits a few cells
+const gridCellSize = 100
+
+// Simulation is the complete state of a match
+type Simulation struct {
+	Map                  gamemap.Map
+	Difficulty           difficulty.Kind
+	Schedule             wave.Schedule
+	Enemies              []*entity.Enemy
+	Towers               []*entity.Tower
+	Projectiles          []entity.Projectile
+	Shop                 *shop.Shop
+	Tick                 int
+	TickRate             int // Steps per second; every Step advances 1/TickRate seconds
+	TowerLimit           int
+	TowerCost            int
+	TowerSellRate        float32
+	Lives                int
+	StartLives           int // Lives the match started with, the most Repair restores
+	Coins                int
+	CoinsEarned          int // Coins won from bounties over the whole match
+	EnemiesDefeated      int
+	DifficultyModifier   int
+	TowerDamageBoost     int
+	TowerFireRateBoost   float32
+	CurrentWave          int
+	WaveActive           bool
+	EnemiesInWave        int // Current wave size while active, next wave preview otherwise
+	EnemiesKilledInWave  int
+	GameOver             bool
+	Abilities            map[AbilityKind]AbilityState
+	GoldRush             float32              // Bounty multiplier of an active gold rush, 0 when none is active
+	Logger               func(message string) // Receives a line for every event of the match, nil to stay quiet
+	pendingSpawns        []wave.Spawn         // Spawns of the active wave not created yet
+	waveElapsed          int                  // Ticks since the active wave started
+	enemiesPerWave       int
+	enemiesSpawnedInWave int
+	enemyGrid            *spatial.Grid[*entity.Enemy] // Enemies by position, rebuilt every tick
+	candidates           []*entity.Enemy              // Reused buffer for grid queries
+}
+
+// New creates a simulation on the given map using the default game
+// constants scaled by the given difficulty preset, which must be
+// registered. The map's scripted waves are played first, then procedural
+// ones.
+func New(m gamemap.Map, kind difficulty.Kind) *Simulation {
+	schedule := wave.Schedule{Waves: m.Waves}
+	preset := difficulty.Presets[kind]
+	lives := max(difficulty.Scale(config.GameConstants.InitialLives, preset.Lives), 1)
+	return &Simulation{
+		Map:                m,
+		Difficulty:         kind,
+		Schedule:           schedule,
+		Enemies:            []*entity.Enemy{},
+		Shop:               shop.NewShop(),
+		TickRate:           config.GameConstants.TickRate,
+		TowerLimit:         config.GameConstants.TowerLimit,
+		TowerCost:          config.GameConstants.InitialTowerCost,
+		TowerSellRate:      config.GameConstants.TowerSellRate,
+		Lives:              lives,
+		StartLives:         lives,
+		Coins:              difficulty.Scale(config.GameConstants.InitialCoins, preset.Coins),
+		EnemiesDefeated:    config.GameConstants.EnemiesDefeated,
+		DifficultyModifier: config.GameConstants.DifficultyModifier,
+		TowerDamageBoost:   config.GameConstants.TowerDamageBoost,
+		TowerFireRateBoost: config.GameConstants.TowerFireRateBoost,
+		EnemiesInWave:      schedule.Wave(1).Size(),
+		Abilities:          newAbilities(),
+		enemyGrid:          spatial.NewGrid[*entity.Enemy](gridCellSize, float32(config.Config.Width), float32(config.Config.Height)),
+	}
+}
+
+// Preset returns the difficulty preset the match is played on
+func (s *Simulation) Preset() difficulty.Preset {
+	return difficulty.Presets[s.Difficulty]
+}
+
+// logf formats a message for the Logger, if any
+func (s *Simulation) logf(format string, args ...any) {
+	if s.Logger != nil {
+		s.Logger(fmt.Sprintf(format, args...))
+	}
+}
+
+// Time returns the simulation time in seconds
+func (s *Simulation) Time() float64 {
+	return s.seconds(s.Tick)
+}
+
+// TickDuration returns the simulated seconds one Step covers
+func (s *Simulation) TickDuration() float32 {
+	return 1 / float32(s.TickRate)
+}
+
+func (s *Simulation) seconds(ticks int) float64 {
+	return float64(ticks) / float64(s.TickRate)
+}
+
+// Step advances the simulation by one tick and then applies the given commands.
+// Commands that fail are skipped; their errors are joined into the result.
+func (s *Simulation) Step(commands []Command) error {
+	if s.GameOver {
+		return nil
+	}
+
+	s.Tick++
+
+	if s.WaveActive {
+		s.updateWave()
+	}
+
+	return s.Apply(commands)
+}
+
+// Apply executes commands without advancing time, so the player can keep
+// building and shopping while the game is paused. Commands that fail are
+// skipped; their errors are joined into the result.
+func (s *Simulation) Apply(commands []Command) error {
+	if s.GameOver {
+		return nil
+	}
+
+	var errs []error
+	for _, cmd := range commands {
+		if err := s.apply(cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// apply executes a single command
+func (s *Simulation) apply(cmd Command) error {
+	switch cmd.Kind {
+	case CommandPlaceTower:
+		return s.placeTower(cmd.X, cmd.Y, cmd.Tower)
+	case CommandSellTower:
+		return s.sellTower(cmd.X, cmd.Y)
+	case CommandUpgradeTower:
+		return s.upgradeTower(cmd.X, cmd.Y)
+	case CommandSetTargeting:
+		return s.setTargeting(cmd.X, cmd.Y, cmd.Target)
+	case CommandBuyItem:
+		return s.buyItem(cmd.ItemID)
+	case CommandStartWave:
+		return s.startNextWave()
+	case CommandUseAbility:
+		return s.useAbility(cmd.Ability, cmd.X, cmd.Y)
+	default:
+		return ErrUnknownCommand
+	}
+}
+
+// updateWave spawns, moves and resolves combat for the active wave
+func (s *Simulation) updateWave() {
+	dt := s.TickDuration()
+
+	// Create every spawn that is due
+	for len(s.pendingSpawns) > 0 && float64(s.pendingSpawns[0].Time) <= s.seconds(s.waveElapsed) {
+		s.spawn(s.pendingSpawns[0])
+		s.pendingSpawns = s.pendingSpawns[1:]
+	}
+	s.waveElapsed++
+
+	// Update all enemies
+	var aliveEnemies []*entity.Enemy
+	for _, enemy := range s.Enemies {
+		if enemy.IsAlive() {
+			// Check if enemy reached the end of the path
+			if enemy.HasEscaped(s.Map) {
+				s.Lives -= enemy.LivesCost
+				s.logf("Enemy escaped! Lives remaining: %d", s.Lives)
+
+				if s.Lives <= 0 {
+					s.GameOver = true
+					s.WaveActive = false
+					s.logf("Game Over!")
+				}
+			} else {
+				enemy.UpdateEffects(dt)
+				enemy.Regenerate(dt)
+				enemy.FollowPath(s.Map, dt)
+				aliveEnemies = append(aliveEnemies, enemy)
+			}
+		} else {
+			bounty := difficulty.Scale(enemy.Bounty, s.bountyMultiplier())
+			s.EnemiesDefeated++
+			s.Coins += bounty
+			s.CoinsEarned += bounty
+			s.EnemiesKilledInWave++
+			s.logf("Enemy defeated! Total: %d, Coins: %d", s.EnemiesDefeated, s.Coins)
+
+			// Splitting enemies leave smaller ones behind, which count towards the wave
+			children := enemy.Split(s.Map)
+			aliveEnemies = append(aliveEnemies, children...)
+			s.EnemiesInWave += len(children)
+		}
+	}
+	s.Enemies = aliveEnemies
+
+	// Check if wave is complete (all enemies spawned and all dead)
+	if len(s.pendingSpawns) == 0 && len(s.Enemies) == 0 {
+		s.WaveActive = false
+		s.EnemiesKilledInWave = 0
+		s.GoldRush = 0
+		// Preview the next wave from the same schedule that will spawn it
+		s.EnemiesInWave = s.Schedule.Wave(s.CurrentWave + 1).Size()
+		s.logf("Wave %d complete!", s.CurrentWave)
+	}
+
+	// Index the enemies where they ended up this tick
+	s.enemyGrid.Clear()
+	for _, enemy := range s.Enemies {
+		s.enemyGrid.Insert(enemy, enemy.PositionX, enemy.PositionY)
+	}
+
+	// Check for tower attacks on the enemies around each tower
+	now := s.Time()
+	for _, tower := range s.Towers {
+		// Apply global fire rate boost
+		if tower.CanFire(now, s.TowerFireRateBoost) {
+			// Only attack one enemy per tower per fire cycle, picked by its targeting mode
+			s.candidates = s.enemyGrid.Query(s.candidates[:0], tower.PositionX, tower.PositionY, tower.Range)
+			if target := tower.SelectTarget(s.candidates, s.Map); target != nil {
+				s.Projectiles = append(s.Projectiles, tower.Attack(target, s.Map))
+				tower.LastFireTime = now
+			}
+		}
+	}
+
+	// Update projectiles
+	var activeProjectiles []entity.Projectile
+	for i := range s.Projectiles {
+		projectile := &s.Projectiles[i]
+		totalDamage := projectile.Damage + s.TowerDamageBoost
+		hits, active := projectile.Update(s.enemyGrid, totalDamage, dt)
+		if hits > 0 {
+			s.logf("Enemy hit! Damage: %d, Enemies hit: %d", totalDamage, hits)
+		}
+		if active {
+			// Projectile still moving
+			activeProjectiles = append(activeProjectiles, *projectile)
+		}
+	}
+	s.Projectiles = activeProjectiles
+}
+
+// startNextWave starts the next wave of enemies
+func (s *Simulation) startNextWave() error {
+	if s.WaveActive {
+		return ErrWaveActive
+	}
+
+	s.CurrentWave++
+	s.WaveActive = true
+
+	// Difficulty goes up every few waves, sooner on the harder presets
+	if modifier := wave.Difficulty(s.CurrentWave, s.Preset().RampEvery); modifier != s.DifficultyModifier {
+		s.DifficultyModifier = modifier
+		s.logf("Difficulty increased! Modifier: %d", s.DifficultyModifier)
+	}
+
+	s.pendingSpawns = s.Schedule.Wave(s.CurrentWave).Spawns(s.CurrentWave, s.DifficultyModifier)
+	s.waveElapsed = 0
+	s.enemiesPerWave = len(s.pendingSpawns)
+	s.enemiesSpawnedInWave = 0
+
+	s.EnemiesInWave = s.enemiesPerWave
+	s.EnemiesKilledInWave = 0
+
+	s.logf("Wave %d started! (%d enemies)", s.CurrentWave, s.enemiesPerWave)
+	return nil
+}
+
+// spawn creates one enemy of the active wave, its life and speed scaled
+// by the difficulty preset. Spawns without a lane are spread across every
+// lane in turn.
+func (s *Simulation) spawn(sp wave.Spawn) {
+	lane := s.enemiesSpawnedInWave % len(s.Map.Lanes)
+	if index, ok := s.Map.LaneIndex(sp.Lane); ok {
+		lane = index
+	}
+
+	preset := s.Preset()
+	enemy := entity.NewEnemy(entity.NewEnemyParams{
+		Map:   s.Map,
+		Lane:  lane,
+		Kind:  entity.EnemyKind(sp.Type),
+		Speed: sp.Speed * preset.EnemySpeed,
+		Life:  max(difficulty.Scale(sp.Life, preset.EnemyLife), 1),
+	})
+	s.Enemies = append(s.Enemies, enemy)
+	s.enemiesSpawnedInWave++
+	s.logf("%s spawned! (%d/%d)", enemy.Type().Name, s.enemiesSpawnedInWave, s.enemiesPerWave)
+}
+
+// bountyMultiplier returns what enemy bounties are multiplied by: the
+// difficulty preset's multiplier, doubled during a gold rush
+func (s *Simulation) bountyMultiplier() float32 {
+	multiplier := s.Preset().Bounty
+	if s.GoldRush > 0 {
+		multiplier *= s.GoldRush
+	}
+	return multiplier
+}
+
+// TowerCostFor returns what placing a tower of the given kind costs
+func (s *Simulation) TowerCostFor(kind entity.TowerKind) int {
+	return int(float32(s.TowerCost) * entity.TowerTypes[kind].Cost)
+}
+
+// TowerAt returns the tower covering (x, y), or nil
+func (s *Simulation) TowerAt(x, y float32) *entity.Tower {
+	for _, tower := range s.Towers {
+		if tower.Contains(x, y) {
+			return tower
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) placeTower(x, y float32, kind entity.TowerKind) error {
+	if _, ok := entity.LookupTowerType(kind); !ok {
+		return ErrUnknownTower
+	}
+
+	// Check if clicking in HUD area
+	if y < config.HUDHeight {
+		return ErrHUDArea
+	}
+
+	// Check if there's already a tower at this position
+	for _, tower := range s.Towers {
+		dx := x - tower.PositionX
+		dy := y - tower.PositionY
+		distance := dx*dx + dy*dy
+		minDistance := config.TowerSize * config.TowerSize
+
+		if distance < minDistance {
+			return ErrTowerOverlap
+		}
+	}
+
+	// Check if tower limit reached
+	if len(s.Towers) >= s.TowerLimit {
+		return ErrTowerLimit
+	}
+
+	// Check if has enough coins
+	cost := s.TowerCostFor(kind)
+	if s.Coins < cost {
+		return ErrNotEnoughCoins
+	}
+
+	if !s.Map.IsBuildable(x, y) {
+		return ErrNotBuildable
+	}
+
+	// Validate tower placement (not on path)
+	if !entity.CanPlaceTower(x, y, s.Map) {
+		return ErrOnPath
+	}
+
+	// Deduct coins and place tower
+	s.Coins -= cost
+	tower := entity.NewTower(x, y, kind, cost)
+	s.logf("%s tower placed at (%.1f, %.1f)! Coins left: %d", tower.Type().Name, x, y, s.Coins)
+	s.Towers = append(s.Towers, tower)
+	return nil
+}
+
+// sellTower removes the tower covering (x, y) and refunds part of what
+// was invested in it
+func (s *Simulation) sellTower(x, y float32) error {
+	for i, tower := range s.Towers {
+		if !tower.Contains(x, y) {
+			continue
+		}
+
+		refund := tower.SellValue(s.TowerSellRate)
+		s.Coins += refund
+
+		// Remove tower
+		s.Towers[i] = s.Towers[len(s.Towers)-1]
+		s.Towers = s.Towers[:len(s.Towers)-1]
+		s.logf("%s tower sold for %d coins! Remaining: %d", tower.Type().Name, refund, len(s.Towers))
+		return nil
+	}
+
+	return ErrNoTower
+}
+
+// upgradeTower buys the next upgrade tier of the tower covering (x, y)
+func (s *Simulation) upgradeTower(x, y float32) error {
+	tower := s.TowerAt(x, y)
+	if tower == nil {
+		return ErrNoTower
+	}
+
+	upgrade, ok := tower.NextUpgrade()
+	if !ok {
+		return ErrMaxLevel
+	}
+
+	if s.Coins < upgrade.Cost {
+		return ErrUpgradeCoins
+	}
+
+	s.Coins -= upgrade.Cost
+	tower.Upgrade()
+	s.logf("%s tower upgraded with %s! Level: %d, Coins left: %d", tower.Type().Name, upgrade.Name, tower.Level, s.Coins)
+	return nil
+}
+
+// setTargeting changes the targeting mode of the tower covering (x, y)
+func (s *Simulation) setTargeting(x, y float32, mode entity.TargetMode) error {
+	tower := s.TowerAt(x, y)
+	if tower == nil {
+		return ErrNoTower
+	}
+
+	tower.Targeting = mode
+	s.logf("%s tower now targets %s", tower.Type().Name, mode)
+	return nil
+}
+
+// buyItem buys a shop item, applying its effect through the Match methods
+// below
+func (s *Simulation) buyItem(itemID string) error {
+	cost, err := s.Shop.PurchaseItem(itemID, s.Coins, s)
+	if err != nil {
+		return err
+	}
+
+	s.Coins -= cost
+	item, _ := s.Shop.Item(itemID)
+	s.logf("Bought %s for %d coins! Coins left: %d", item.Name, cost, s.Coins)
+	return nil
+}
+
+// AddTowerSlots raises the tower limit and with it the tower cost, which
+// grows in proportion to the limit from the configured starting values
+func (s *Simulation) AddTowerSlots(n int) {
+	s.TowerLimit += n
+	// A configured limit of 0 prices the first slot at the starting cost
+	baseLimit := max(config.GameConstants.TowerLimit, 1)
+	s.TowerCost = config.GameConstants.InitialTowerCost * s.TowerLimit / baseLimit
+	s.logf("Bought tower slot! New limit: %d", s.TowerLimit)
+}
+
+// AddTowerDamage adds damage to every tower
+func (s *Simulation) AddTowerDamage(n int) {
+	s.TowerDamageBoost += n
+	s.logf("Tower damage increased! Total bonus: +%d", s.TowerDamageBoost)
+}
+
+// AddTowerFireRate raises the fire rate multiplier of every tower
+func (s *Simulation) AddTowerFireRate(rate float32) {
+	s.TowerFireRateBoost += rate
+	s.logf("Tower fire rate increased! Multiplier: %.1fx", s.TowerFireRateBoost)
+}
+
+// AddLives restores lives
+func (s *Simulation) AddLives(n int) {
+	s.Lives += n
+	s.logf("Lives restored! Lives: %d", s.Lives)
+}
@@ -0,0 +1,315 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/entity"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// testMap is a straight lane along y = 400 with a strip above it where
+// towers may be built. Its only scripted wave sends one grunt with the
+// given speed and life.
+func testMap(speed float32, life int) gamemap.Map {
+	return gamemap.Map{
+		Name: "Test",
+		Lanes: []gamemap.Lane{{
+			Name:  "main",
+			Paths: []gamemap.Path{{StartX: 0, StartY: 400, EndX: 600, EndY: 400}},
+		}},
+		Buildable: []gamemap.Region{{X: 0, Y: 300, Width: 800, Height: 80}},
+		Waves:     []wave.Wave{{Groups: []wave.Group{{Count: 1, Speed: speed, Life: life}}}},
+	}
+}
+
+// stepUntil steps s until done reports true, failing after a minute of
+// game time
+func stepUntil(t *testing.T, s *Simulation, done func() bool) {
+	t.Helper()
+	for i := 0; i < 60*s.TickRate; i++ {
+		if done() {
+			return
+		}
+		if err := s.Step(nil); err != nil {
+			t.Fatalf("tick %d: %v", s.Tick, err)
+		}
+	}
+	t.Fatal("condition not reached after a minute of game time")
+}
+
+func TestPlaceTower(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y float32
+		err  error
+	}{
+		{name: "buildable spot", x: 300, y: 340},
+		{name: "HUD area", x: 300, y: 100, err: ErrHUDArea},
+		{name: "outside the buildable area", x: 300, y: 200, err: ErrNotBuildable},
+		{name: "on the path", x: 300, y: 380, err: ErrOnPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(testMap(30, 1), difficulty.Normal)
+			coins := s.Coins
+
+			err := s.Step([]Command{PlaceTower(tt.x, tt.y, entity.TowerGun)})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				if len(s.Towers) != 0 || s.Coins != coins {
+					t.Errorf("rejected placement left %d towers and %d coins", len(s.Towers), s.Coins)
+				}
+				return
+			}
+			if len(s.Towers) != 1 {
+				t.Fatalf("got %d towers, want 1", len(s.Towers))
+			}
+			if want := coins - s.TowerCostFor(entity.TowerGun); s.Coins != want {
+				t.Errorf("got %d coins, want %d", s.Coins, want)
+			}
+		})
+	}
+}
+
+func TestStartWave(t *testing.T) {
+	s := New(testMap(30, 1), difficulty.Normal)
+
+	if err := s.Step([]Command{StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.WaveActive || s.CurrentWave != 1 || s.EnemiesInWave != 1 {
+		t.Fatalf("got active %v, wave %d, %d enemies; want true, 1, 1", s.WaveActive, s.CurrentWave, s.EnemiesInWave)
+	}
+
+	if err := s.Step([]Command{StartWave()}); !errors.Is(err, ErrWaveActive) {
+		t.Errorf("second start: got %v, want %v", err, ErrWaveActive)
+	}
+	if len(s.Enemies) != 1 {
+		t.Errorf("got %d enemies after the first tick, want 1", len(s.Enemies))
+	}
+}
+
+func TestEnemyKilledPaysBounty(t *testing.T) {
+	s := New(testMap(30, 1), difficulty.Normal)
+	if err := s.Step([]Command{PlaceTower(300, 340, entity.TowerGun), StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+	coins, lives := s.Coins, s.Lives
+
+	stepUntil(t, s, func() bool { return !s.WaveActive })
+
+	bounty := difficulty.Scale(entity.EnemyTypes[entity.EnemyBasic].Bounty, s.Preset().Bounty)
+	if s.Coins != coins+bounty || s.CoinsEarned != bounty {
+		t.Errorf("got %d coins (%d earned), want %d (%d earned)", s.Coins, s.CoinsEarned, coins+bounty, bounty)
+	}
+	if s.Lives != lives {
+		t.Errorf("got %d lives, want %d", s.Lives, lives)
+	}
+	if s.Towers[0].Kills != 1 {
+		t.Errorf("tower has %d kills, want 1", s.Towers[0].Kills)
+	}
+}
+
+func TestEnemyEscapeCostsLives(t *testing.T) {
+	s := New(testMap(600, 100), difficulty.Normal)
+	if err := s.Step([]Command{StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+	coins, lives := s.Coins, s.Lives
+
+	stepUntil(t, s, func() bool { return !s.WaveActive })
+
+	if want := lives - entity.EnemyTypes[entity.EnemyBasic].LivesCost; s.Lives != want {
+		t.Errorf("got %d lives, want %d", s.Lives, want)
+	}
+	if s.Coins != coins {
+		t.Errorf("got %d coins, want %d", s.Coins, coins)
+	}
+	if s.GameOver {
+		t.Error("game over with lives left")
+	}
+}
+
+func TestGameOver(t *testing.T) {
+	s := New(testMap(600, 100), difficulty.Normal)
+	s.Lives = 1
+	if err := s.Step([]Command{StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+
+	stepUntil(t, s, func() bool { return s.GameOver })
+
+	if s.Lives > 0 || s.WaveActive {
+		t.Errorf("got %d lives and active %v at game over", s.Lives, s.WaveActive)
+	}
+
+	// A finished match ignores time and commands
+	tick, coins := s.Tick, s.Coins
+	if err := s.Step([]Command{PlaceTower(300, 340, entity.TowerGun)}); err != nil {
+		t.Errorf("step after game over: %v", err)
+	}
+	if s.Tick != tick || s.Coins != coins || len(s.Towers) != 0 {
+		t.Error("step after game over changed the match")
+	}
+}
+
+func TestTowerSlotCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		cost      int
+		slots     int
+		wantLimit int
+		wantCost  int
+	}{
+		{name: "default constants", limit: 3, cost: 15, slots: 1, wantLimit: 4, wantCost: 20},
+		{name: "configured cost", limit: 3, cost: 40, slots: 1, wantLimit: 4, wantCost: 53},
+		{name: "configured cost, two slots", limit: 3, cost: 40, slots: 2, wantLimit: 5, wantCost: 66},
+		{name: "no starting slots", limit: 0, cost: 40, slots: 2, wantLimit: 2, wantCost: 80},
+	}
+
+	defaults := config.GameConstants
+	t.Cleanup(func() { config.GameConstants = defaults })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GameConstants.TowerLimit = tt.limit
+			config.GameConstants.InitialTowerCost = tt.cost
+			s := New(testMap(30, 1), difficulty.Normal)
+			s.Coins = 10000
+
+			for i := 0; i < tt.slots; i++ {
+				if err := s.Step([]Command{BuyItem("towerSlot")}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if s.TowerLimit != tt.wantLimit || s.TowerCost != tt.wantCost {
+				t.Errorf("got limit %d, cost %d; want %d, %d", s.TowerLimit, s.TowerCost, tt.wantLimit, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestAbilityCooldown(t *testing.T) {
+	s := New(testMap(10, 10000), difficulty.Normal)
+	if err := s.Step([]Command{StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Apply([]Command{UseAbility(AbilityAirstrike, 0, 400)}); err != nil {
+		t.Fatal(err)
+	}
+
+	cooldown := int(math.Ceil(float64(AbilityTypes[AbilityAirstrike].Cooldown) * float64(s.TickRate)))
+	for i := 0; i < cooldown-1; i++ {
+		if err := s.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Apply([]Command{UseAbility(AbilityAirstrike, 0, 400)}); !errors.Is(err, ErrAbilityCooling) {
+		t.Fatalf("one tick early: got %v, want %v", err, ErrAbilityCooling)
+	}
+	if s.CooldownLeft(AbilityAirstrike) <= 0 {
+		t.Error("no cooldown left one tick early")
+	}
+
+	if err := s.Step([]Command{UseAbility(AbilityAirstrike, 0, 400)}); err != nil {
+		t.Errorf("after %d ticks: %v", cooldown, err)
+	}
+}
+
+func TestAbilityCharges(t *testing.T) {
+	s := New(testMap(30, 1), difficulty.Normal)
+	s.Lives = 1
+
+	charges := AbilityTypes[AbilityRepair].Charges
+	for i := 0; i < charges; i++ {
+		if err := s.Apply([]Command{UseAbility(AbilityRepair, 0, 0)}); err != nil {
+			t.Fatalf("use %d: %v", i+1, err)
+		}
+	}
+	if err := s.Apply([]Command{UseAbility(AbilityRepair, 0, 0)}); !errors.Is(err, ErrAbilityCharges) {
+		t.Fatalf("got %v, want %v", err, ErrAbilityCharges)
+	}
+	if s.ChargesLeft(AbilityRepair) != 0 || s.Lives != 1+charges {
+		t.Errorf("got %d charges, %d lives; want 0, %d", s.ChargesLeft(AbilityRepair), s.Lives, 1+charges)
+	}
+}
+
+func TestRepairCap(t *testing.T) {
+	s := New(testMap(30, 1), difficulty.Normal)
+	charges := s.ChargesLeft(AbilityRepair)
+
+	if err := s.Apply([]Command{UseAbility(AbilityRepair, 0, 0)}); !errors.Is(err, ErrLivesFull) {
+		t.Fatalf("full lives: got %v, want %v", err, ErrLivesFull)
+	}
+	if s.Lives != s.StartLives || s.ChargesLeft(AbilityRepair) != charges {
+		t.Fatalf("refused repair changed lives to %d and charges to %d", s.Lives, s.ChargesLeft(AbilityRepair))
+	}
+
+	s.Lives = s.StartLives - 1
+	if err := s.Apply([]Command{UseAbility(AbilityRepair, 0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Lives != s.StartLives || s.ChargesLeft(AbilityRepair) != charges-1 {
+		t.Errorf("got %d lives, %d charges; want %d, %d", s.Lives, s.ChargesLeft(AbilityRepair), s.StartLives, charges-1)
+	}
+}
+
+func TestGoldRush(t *testing.T) {
+	s := New(testMap(600, 100), difficulty.Normal)
+	if err := s.Step([]Command{StartWave(), UseAbility(AbilityGoldRush, 0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Apply([]Command{UseAbility(AbilityGoldRush, 0, 0)}); !errors.Is(err, ErrGoldRushActive) {
+		t.Fatalf("second use: got %v, want %v", err, ErrGoldRushActive)
+	}
+	if s.ChargesLeft(AbilityGoldRush) != AbilityTypes[AbilityGoldRush].Charges-1 {
+		t.Errorf("blocked use spent a charge: %d left", s.ChargesLeft(AbilityGoldRush))
+	}
+
+	stepUntil(t, s, func() bool { return !s.WaveActive })
+	if s.GoldRush != 0 {
+		t.Fatalf("gold rush %v still active after the wave", s.GoldRush)
+	}
+
+	if err := s.Step([]Command{StartWave(), UseAbility(AbilityGoldRush, 0, 0)}); err != nil {
+		t.Errorf("next wave: %v", err)
+	}
+}
+
+func TestFreezeStunsEveryEnemy(t *testing.T) {
+	s := New(busyMap(), difficulty.Normal)
+	if err := s.Step([]Command{StartWave()}); err != nil {
+		t.Fatal(err)
+	}
+	stepUntil(t, s, func() bool { return len(s.Enemies) >= 4 })
+
+	if err := s.Apply([]Command{UseAbility(AbilityFreeze, 0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	positions := make([]float32, len(s.Enemies))
+	for i, enemy := range s.Enemies {
+		if !enemy.HasEffect(entity.EffectStun) {
+			t.Errorf("enemy %d is not stunned", i)
+		}
+		positions[i] = enemy.PositionX
+	}
+
+	if err := s.Step(nil); err != nil {
+		t.Fatal(err)
+	}
+	for i, x := range positions {
+		if s.Enemies[i].PositionX != x {
+			t.Errorf("stunned enemy %d moved from %v to %v", i, x, s.Enemies[i].PositionX)
+		}
+	}
+}
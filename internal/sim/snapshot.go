@@ -0,0 +1,173 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/entity"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/shop"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// Snapshot is the complete saved state of a match. The map is saved by
+// name; Restore is given the map itself.
+type Snapshot struct {
+	Map                  string                       `json:"map"`
+	Difficulty           difficulty.Kind              `json:"difficulty"`
+	Schedule             wave.Schedule                `json:"schedule"`
+	Tick                 int                          `json:"tick"`
+	TickRate             int                          `json:"tickRate"`
+	TowerLimit           int                          `json:"towerLimit"`
+	TowerCost            int                          `json:"towerCost"`
+	TowerSellRate        float32                      `json:"towerSellRate"`
+	Lives                int                          `json:"lives"`
+	StartLives           int                          `json:"startLives"`
+	Coins                int                          `json:"coins"`
+	CoinsEarned          int                          `json:"coinsEarned"`
+	EnemiesDefeated      int                          `json:"enemiesDefeated"`
+	DifficultyModifier   int                          `json:"difficultyModifier"`
+	TowerDamageBoost     int                          `json:"towerDamageBoost"`
+	TowerFireRateBoost   float32                      `json:"towerFireRateBoost"`
+	CurrentWave          int                          `json:"currentWave"`
+	WaveActive           bool                         `json:"waveActive"`
+	EnemiesInWave        int                          `json:"enemiesInWave"`
+	EnemiesKilledInWave  int                          `json:"enemiesKilledInWave"`
+	ShopPurchases        map[string]int               `json:"shopPurchases"` // Times each shop item was bought, by ID
+	Abilities            map[AbilityKind]AbilityState `json:"abilities"`
+	GoldRush             float32                      `json:"goldRush"`
+	Towers               []entity.TowerState          `json:"towers"`
+	Enemies              []entity.EnemyState          `json:"enemies"`
+	Projectiles          []entity.ProjectileState     `json:"projectiles"`
+	PendingSpawns        []wave.Spawn                 `json:"pendingSpawns"`
+	WaveElapsed          int                          `json:"waveElapsed"`
+	EnemiesPerWave       int                          `json:"enemiesPerWave"`
+	EnemiesSpawnedInWave int                          `json:"enemiesSpawnedInWave"`
+}
+
+// Snapshot captures the match so it can be saved and restored later
+func (s *Simulation) Snapshot() Snapshot {
+	index := entity.NewIndex(s.Towers, s.Enemies)
+
+	snap := Snapshot{
+		Map:                  s.Map.Name,
+		Difficulty:           s.Difficulty,
+		Schedule:             s.Schedule,
+		Tick:                 s.Tick,
+		TickRate:             s.TickRate,
+		TowerLimit:           s.TowerLimit,
+		TowerCost:            s.TowerCost,
+		TowerSellRate:        s.TowerSellRate,
+		Lives:                s.Lives,
+		StartLives:           s.StartLives,
+		Coins:                s.Coins,
+		CoinsEarned:          s.CoinsEarned,
+		EnemiesDefeated:      s.EnemiesDefeated,
+		DifficultyModifier:   s.DifficultyModifier,
+		TowerDamageBoost:     s.TowerDamageBoost,
+		TowerFireRateBoost:   s.TowerFireRateBoost,
+		CurrentWave:          s.CurrentWave,
+		WaveActive:           s.WaveActive,
+		EnemiesInWave:        s.EnemiesInWave,
+		EnemiesKilledInWave:  s.EnemiesKilledInWave,
+		ShopPurchases:        make(map[string]int, len(s.Shop.Items)),
+		Abilities:            maps.Clone(s.Abilities),
+		GoldRush:             s.GoldRush,
+		PendingSpawns:        s.pendingSpawns,
+		WaveElapsed:          s.waveElapsed,
+		EnemiesPerWave:       s.enemiesPerWave,
+		EnemiesSpawnedInWave: s.enemiesSpawnedInWave,
+	}
+
+	for _, item := range s.Shop.Items {
+		snap.ShopPurchases[item.ID] = item.Bought
+	}
+	for _, tower := range s.Towers {
+		snap.Towers = append(snap.Towers, tower.State())
+	}
+	for _, enemy := range s.Enemies {
+		snap.Enemies = append(snap.Enemies, enemy.State(index))
+	}
+	for i := range s.Projectiles {
+		snap.Projectiles = append(snap.Projectiles, s.Projectiles[i].State(index))
+	}
+
+	return snap
+}
+
+// Restore rebuilds a match from a snapshot taken on m. It fails when the
+// snapshot refers to lanes, towers, enemy types, shop items, abilities or
+// a difficulty preset that do not exist.
+func Restore(m gamemap.Map, snap Snapshot) (*Simulation, error) {
+	if snap.Map != m.Name {
+		return nil, fmt.Errorf("snapshot is for map %q, not %q", snap.Map, m.Name)
+	}
+
+	if _, ok := difficulty.Lookup(snap.Difficulty); !ok {
+		return nil, fmt.Errorf("unknown difficulty %q", snap.Difficulty)
+	}
+
+	s := New(m, snap.Difficulty)
+	s.Schedule = snap.Schedule
+	s.Tick = snap.Tick
+	s.TickRate = max(snap.TickRate, 1)
+	s.TowerLimit = snap.TowerLimit
+	s.TowerCost = snap.TowerCost
+	s.TowerSellRate = snap.TowerSellRate
+	s.Lives = snap.Lives
+	s.StartLives = snap.StartLives
+	s.Coins = snap.Coins
+	s.CoinsEarned = snap.CoinsEarned
+	s.EnemiesDefeated = snap.EnemiesDefeated
+	s.DifficultyModifier = snap.DifficultyModifier
+	s.TowerDamageBoost = snap.TowerDamageBoost
+	s.TowerFireRateBoost = snap.TowerFireRateBoost
+	s.CurrentWave = snap.CurrentWave
+	s.WaveActive = snap.WaveActive
+	s.EnemiesInWave = snap.EnemiesInWave
+	s.EnemiesKilledInWave = snap.EnemiesKilledInWave
+	s.pendingSpawns = snap.PendingSpawns
+	s.waveElapsed = snap.WaveElapsed
+	s.enemiesPerWave = snap.EnemiesPerWave
+	s.enemiesSpawnedInWave = snap.EnemiesSpawnedInWave
+	s.GoldRush = snap.GoldRush
+
+	// Saves made before abilities existed keep them ready and fully charged
+	for kind, state := range snap.Abilities {
+		if _, ok := LookupAbilityType(kind); !ok {
+			return nil, fmt.Errorf("unknown ability %q", kind)
+		}
+		s.Abilities[kind] = state
+	}
+
+	s.Shop = shop.NewShop()
+	for id, bought := range snap.ShopPurchases {
+		item, ok := s.Shop.Item(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown shop item %q", id)
+		}
+		item.Bought = bought
+	}
+
+	for _, state := range snap.Towers {
+		if _, ok := entity.LookupTowerType(state.Kind); !ok {
+			return nil, fmt.Errorf("unknown tower type %q", state.Kind)
+		}
+		s.Towers = append(s.Towers, state.Restore())
+	}
+	for _, state := range snap.Enemies {
+		if _, ok := entity.LookupEnemyType(state.Kind); !ok {
+			return nil, fmt.Errorf("unknown enemy type %q", state.Kind)
+		}
+		if state.Lane < 0 || state.Lane >= len(m.Lanes) {
+			return nil, fmt.Errorf("enemy on unknown lane %d", state.Lane)
+		}
+		s.Enemies = append(s.Enemies, state.Restore(s.Towers))
+	}
+	for _, state := range snap.Projectiles {
+		s.Projectiles = append(s.Projectiles, state.Restore(s.Towers, s.Enemies))
+	}
+
+	return s, nil
+}
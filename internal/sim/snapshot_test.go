@@ -0,0 +1,170 @@
+package sim
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nx23/final-path/internal/difficulty"
+	"github.com/nx23/final-path/internal/entity"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/wave"
+)
+
+// busyMap is a long lane along y = 400, buildable anywhere, whose first
+// wave sends a stream of sturdy grunts past several towers
+func busyMap() gamemap.Map {
+	return gamemap.Map{
+		Name: "Busy",
+		Lanes: []gamemap.Lane{{
+			Name:  "main",
+			Paths: []gamemap.Path{{StartX: 0, StartY: 400, EndX: 700, EndY: 400}},
+		}},
+		Waves: []wave.Wave{{Groups: []wave.Group{{Count: 8, Interval: 0.4, Speed: 40, Life: 400}}}},
+	}
+}
+
+// busyMatch returns a match in the middle of a wave: towers of every kind
+// firing, projectiles in flight, one of them past a pierced enemy, burns
+// on the enemies and abilities used
+func busyMatch(t *testing.T) *Simulation {
+	t.Helper()
+	s := New(busyMap(), difficulty.Normal)
+	s.Coins = 10000
+	s.TowerLimit = 10
+
+	commands := []Command{BuyItem("towerDamage"), StartWave()}
+	for i, kind := range entity.TowerKinds {
+		commands = append(commands, PlaceTower(float32(60+i*70), 340, kind))
+	}
+	commands = append(commands, PlaceTower(160, 500, entity.TowerCannon))
+	if err := s.Step(commands); err != nil {
+		t.Fatal(err)
+	}
+
+	burning := func() bool {
+		for _, enemy := range s.Enemies {
+			for _, fx := range enemy.Effects {
+				if fx.Kind == entity.EffectBurn {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	stepUntil(t, s, burning)
+
+	if err := s.Step([]Command{UseAbility(AbilityGoldRush, 0, 0), UseAbility(AbilityAirstrike, 200, 425)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for a sniper bolt that already went through an enemy, with
+	// damage over time still running
+	stepUntil(t, s, func() bool {
+		for _, projectile := range s.Snapshot().Projectiles {
+			if len(projectile.Hit) > 0 {
+				return burning()
+			}
+		}
+		return false
+	})
+	return s
+}
+
+// roundTrip saves s as JSON and restores it
+func roundTrip(t *testing.T, s *Simulation) *Simulation {
+	t.Helper()
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Restore(s.Map, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return restored
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	original := busyMatch(t)
+	if len(original.Enemies) == 0 || len(original.Projectiles) == 0 {
+		t.Fatal("match is not busy enough to test")
+	}
+	restored := roundTrip(t, original)
+
+	if !reflect.DeepEqual(original.Snapshot(), restored.Snapshot()) {
+		t.Fatal("restored match differs from the original")
+	}
+
+	// Both matches must play on identically, which only holds when effect
+	// sources, projectile targets and pierced enemies were relinked
+	for i := 0; i < 5*original.TickRate; i++ {
+		if err := original.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := restored.Step(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if original.Lives != restored.Lives || original.Coins != restored.Coins {
+		t.Errorf("got lives %d, coins %d; want %d, %d", restored.Lives, restored.Coins, original.Lives, original.Coins)
+	}
+	if len(original.Enemies) != len(restored.Enemies) || len(original.Projectiles) != len(restored.Projectiles) {
+		t.Errorf("got %d enemies, %d projectiles; want %d, %d",
+			len(restored.Enemies), len(restored.Projectiles), len(original.Enemies), len(original.Projectiles))
+	}
+	want, got := original.Snapshot(), restored.Snapshot()
+	if !reflect.DeepEqual(want.Enemies, got.Enemies) {
+		t.Error("enemies diverged after the restore")
+	}
+	if !reflect.DeepEqual(want.Projectiles, got.Projectiles) {
+		t.Error("projectiles diverged after the restore")
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Error("matches diverged after the restore")
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(snap *Snapshot)
+		err    string
+	}{
+		{name: "other map", change: func(snap *Snapshot) { snap.Map = "Elsewhere" }, err: `snapshot is for map "Elsewhere"`},
+		{name: "unknown difficulty", change: func(snap *Snapshot) { snap.Difficulty = "impossible" }, err: `unknown difficulty "impossible"`},
+		{name: "missing difficulty", change: func(snap *Snapshot) { snap.Difficulty = "" }, err: `unknown difficulty ""`},
+		{name: "unknown tower", change: func(snap *Snapshot) { snap.Towers[0].Kind = "laser" }, err: `unknown tower type "laser"`},
+		{name: "unknown enemy", change: func(snap *Snapshot) { snap.Enemies[0].Kind = "dragon" }, err: `unknown enemy type "dragon"`},
+		{name: "unknown lane", change: func(snap *Snapshot) { snap.Enemies[0].Lane = 3 }, err: "enemy on unknown lane 3"},
+		{name: "negative lane", change: func(snap *Snapshot) { snap.Enemies[0].Lane = -1 }, err: "enemy on unknown lane -1"},
+		{name: "unknown shop item", change: func(snap *Snapshot) { snap.ShopPurchases["jetpack"] = 1 }, err: `unknown shop item "jetpack"`},
+		{name: "unknown ability", change: func(snap *Snapshot) { snap.Abilities["meteor"] = AbilityState{} }, err: `unknown ability "meteor"`},
+	}
+
+	s := busyMatch(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each case changes its own copy, decoded from the same save
+			data, err := json.Marshal(s.Snapshot())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var snap Snapshot
+			if err := json.Unmarshal(data, &snap); err != nil {
+				t.Fatal(err)
+			}
+			tt.change(&snap)
+
+			if _, err := Restore(s.Map, snap); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
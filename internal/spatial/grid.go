@@ -0,0 +1,91 @@
+// Package spatial provides a uniform grid spatial hash for range and
+// radius queries. The simulation rebuilds it every tick so towers and
+// area effects only look at nearby enemies instead of scanning them all.
+package spatial
+
+type entry[T any] struct {
+	item T
+	x, y float32
+}
+
+// Grid buckets items by position into square cells covering a fixed area.
+// Items outside the area are kept in the nearest edge cells, so queries
+// stay exact anywhere; they are just slower far from the area.
+type Grid[T any] struct {
+	cellSize float32
+	columns  int
+	rows     int
+	cells    [][]entry[T]
+	count    int
+}
+
+// NewGrid creates an empty grid covering width x height from the origin.
+// cellSize should be close to the typical query radius: smaller cells mean
+// more buckets per query, larger ones more items to filter.
+func NewGrid[T any](cellSize, width, height float32) *Grid[T] {
+	columns := max(int(width/cellSize)+1, 1)
+	rows := max(int(height/cellSize)+1, 1)
+	return &Grid[T]{
+		cellSize: cellSize,
+		columns:  columns,
+		rows:     rows,
+		cells:    make([][]entry[T], columns*rows),
+	}
+}
+
+// Clear removes every item while keeping the allocated buckets for reuse
+func (g *Grid[T]) Clear() {
+	for i := range g.cells {
+		g.cells[i] = g.cells[i][:0]
+	}
+	g.count = 0
+}
+
+// Len returns the number of items in the grid
+func (g *Grid[T]) Len() int {
+	return g.count
+}
+
+// Insert adds an item at (x, y)
+func (g *Grid[T]) Insert(item T, x, y float32) {
+	i := g.row(y)*g.columns + g.column(x)
+	g.cells[i] = append(g.cells[i], entry[T]{item: item, x: x, y: y})
+	g.count++
+}
+
+// Query appends to dst every item within radius of (x, y) and returns the
+// extended slice. Results are ordered by cell, then by insertion, so the
+// same grid always answers the same query in the same order.
+func (g *Grid[T]) Query(dst []T, x, y, radius float32) []T {
+	minColumn, maxColumn := g.column(x-radius), g.column(x+radius)
+	minRow, maxRow := g.row(y-radius), g.row(y+radius)
+	radiusSquared := radius * radius
+
+	for row := minRow; row <= maxRow; row++ {
+		for column := minColumn; column <= maxColumn; column++ {
+			for _, e := range g.cells[row*g.columns+column] {
+				dx := e.x - x
+				dy := e.y - y
+				if dx*dx+dy*dy <= radiusSquared {
+					dst = append(dst, e.item)
+				}
+			}
+		}
+	}
+
+	return dst
+}
+
+// column returns the column holding x, clamped to the grid
+func (g *Grid[T]) column(x float32) int {
+	return clamp(int(x/g.cellSize), g.columns)
+}
+
+// row returns the row holding y, clamped to the grid
+func (g *Grid[T]) row(y float32) int {
+	return clamp(int(y/g.cellSize), g.rows)
+}
+
+func clamp(i, n int) int {
+	return min(max(i, 0), n-1)
+}
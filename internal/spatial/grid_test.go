@@ -0,0 +1,202 @@
+package spatial
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Tests check the grid against the linear scan it replaces and benchmarks
+// compare the two, on a field the size of the game window. Run the
+// benchmarks with:
+//
+//	go test -bench . ./internal/spatial
+
+const (
+	fieldWidth  = 800
+	fieldHeight = 600
+	cellSize    = 100
+	towerRange  = 150
+	towerCount  = 40
+)
+
+var sizes = []int{100, 1000, 5000, 10000}
+
+type point struct {
+	x, y float32
+}
+
+// randomPoints returns n points spread over the field. The seed is fixed
+// so every run benchmarks the same layout.
+func randomPoints(n int, seed int64) []point {
+	rng := rand.New(rand.NewSource(seed))
+	points := make([]point, n)
+	for i := range points {
+		points[i] = point{rng.Float32() * fieldWidth, rng.Float32() * fieldHeight}
+	}
+	return points
+}
+
+func buildGrid(points []point) *Grid[int] {
+	grid := NewGrid[int](cellSize, fieldWidth, fieldHeight)
+	for i, p := range points {
+		grid.Insert(i, p.x, p.y)
+	}
+	return grid
+}
+
+func linearQuery(dst []int, points []point, x, y, radius float32) []int {
+	for i, p := range points {
+		dx := p.x - x
+		dy := p.y - y
+		if dx*dx+dy*dy <= radius*radius {
+			dst = append(dst, i)
+		}
+	}
+	return dst
+}
+
+// scatteredPoints returns n points over the field and a band around it,
+// so some land in the clamped edge cells
+func scatteredPoints(n int, seed int64) []point {
+	const margin = 200
+	rng := rand.New(rand.NewSource(seed))
+	points := make([]point, n)
+	for i := range points {
+		points[i] = point{
+			rng.Float32()*(fieldWidth+2*margin) - margin,
+			rng.Float32()*(fieldHeight+2*margin) - margin,
+		}
+	}
+	return points
+}
+
+// checkQuery compares a grid query with the linear scan, ignoring order
+func checkQuery(t *testing.T, grid *Grid[int], points []point, x, y, radius float32) {
+	t.Helper()
+	got := grid.Query(nil, x, y, radius)
+	want := linearQuery(nil, points, x, y, radius)
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("query (%v, %v) radius %v: got %d items %v, want %d items %v", x, y, radius, len(got), got, len(want), want)
+	}
+}
+
+func TestQueryMatchesLinearScan(t *testing.T) {
+	points := scatteredPoints(500, 3)
+	grid := buildGrid(points)
+
+	tests := []struct {
+		name   string
+		x, y   float32
+		radius float32
+	}{
+		{name: "inside one cell", x: 250, y: 250, radius: 30},
+		{name: "on a cell corner", x: 300, y: 300, radius: 60},
+		{name: "spanning several cells", x: 400, y: 300, radius: 250},
+		{name: "covering the whole field", x: 400, y: 300, radius: 1000},
+		{name: "edge of the field", x: 0, y: 0, radius: 150},
+		{name: "outside the field", x: -150, y: 700, radius: 120},
+		{name: "far right of the field", x: 950, y: 300, radius: 100},
+		{name: "zero radius", x: points[0].x, y: points[0].y, radius: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkQuery(t, grid, points, tt.x, tt.y, tt.radius)
+		})
+	}
+}
+
+func TestQueryAfterRebuild(t *testing.T) {
+	points := scatteredPoints(300, 4)
+	grid := buildGrid(points)
+
+	// Move every point, as enemies do between ticks, and rebuild
+	rng := rand.New(rand.NewSource(5))
+	for i := range points {
+		points[i].x += rng.Float32()*300 - 150
+		points[i].y += rng.Float32()*300 - 150
+	}
+	grid.Clear()
+	for i, p := range points {
+		grid.Insert(i, p.x, p.y)
+	}
+
+	if grid.Len() != len(points) {
+		t.Fatalf("got %d items after the rebuild, want %d", grid.Len(), len(points))
+	}
+	for _, q := range randomPoints(50, 6) {
+		checkQuery(t, grid, points, q.x, q.y, towerRange)
+	}
+}
+
+// BenchmarkRebuild measures clearing and refilling the grid, the cost the
+// simulation pays once per tick
+func BenchmarkRebuild(b *testing.B) {
+	for _, n := range sizes {
+		points := randomPoints(n, 1)
+		b.Run(fmt.Sprintf("enemies=%d", n), func(b *testing.B) {
+			grid := buildGrid(points)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				grid.Clear()
+				for j, p := range points {
+					grid.Insert(j, p.x, p.y)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkQuery measures a single tower range query
+func BenchmarkQuery(b *testing.B) {
+	for _, n := range sizes {
+		points := randomPoints(n, 1)
+		grid := buildGrid(points)
+		var dst []int
+
+		b.Run(fmt.Sprintf("grid/enemies=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dst = grid.Query(dst[:0], fieldWidth/2, fieldHeight/2, towerRange)
+			}
+		})
+		b.Run(fmt.Sprintf("linear/enemies=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dst = linearQuery(dst[:0], points, fieldWidth/2, fieldHeight/2, towerRange)
+			}
+		})
+	}
+}
+
+// BenchmarkTick measures one tick of targeting: rebuild the grid, then
+// query it once per tower. The linear variant scans every enemy per tower.
+func BenchmarkTick(b *testing.B) {
+	towers := randomPoints(towerCount, 2)
+	for _, n := range sizes {
+		points := randomPoints(n, 1)
+		var dst []int
+
+		b.Run(fmt.Sprintf("grid/enemies=%d", n), func(b *testing.B) {
+			grid := buildGrid(points)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				grid.Clear()
+				for j, p := range points {
+					grid.Insert(j, p.x, p.y)
+				}
+				for _, t := range towers {
+					dst = grid.Query(dst[:0], t.x, t.y, towerRange)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("linear/enemies=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, t := range towers {
+					dst = linearQuery(dst[:0], points, t.x, t.y, towerRange)
+				}
+			}
+		})
+	}
+}
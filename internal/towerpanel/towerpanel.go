@@ -0,0 +1,85 @@
+// Package towerpanel holds the state of the info panel shown for a placed
+// tower. Like the shop it has no Ebiten dependency; the renderer draws it.
+package towerpanel
+
+// Action is what a click on one of the panel's buttons asks for
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionUpgrade
+	ActionSell
+	ActionTargeting
+)
+
+// Button is a clickable area, positioned relative to the panel
+type Button struct {
+	Action Action
+	X      float32
+	Y      float32
+	Width  float32
+	Height float32
+}
+
+// Panel shows the stats of the selected tower with targeting, upgrade and
+// sell buttons
+type Panel struct {
+	Open    bool
+	TowerX  float32 // Center of the selected tower, used to address it in commands
+	TowerY  float32
+	X       float32
+	Y       float32
+	Width   float32
+	Height  float32
+	Buttons []Button
+}
+
+func NewPanel() *Panel {
+	return &Panel{
+		Open:   false,
+		X:      570,
+		Y:      130,
+		Width:  220,
+		Height: 280,
+		Buttons: []Button{
+			{Action: ActionTargeting, X: 10, Y: 160, Width: 200, Height: 30},
+			{Action: ActionUpgrade, X: 10, Y: 200, Width: 200, Height: 30},
+			{Action: ActionSell, X: 10, Y: 240, Width: 200, Height: 30},
+		},
+	}
+}
+
+// Show opens the panel for the tower centered at (x, y)
+func (p *Panel) Show(x, y float32) {
+	p.Open = true
+	p.TowerX = x
+	p.TowerY = y
+}
+
+// Close closes the panel
+func (p *Panel) Close() {
+	p.Open = false
+}
+
+// Contains reports whether (mx, my) is over the open panel
+func (p *Panel) Contains(mx, my int) bool {
+	fx, fy := float32(mx), float32(my)
+	return p.Open && fx >= p.X && fx <= p.X+p.Width && fy >= p.Y && fy <= p.Y+p.Height
+}
+
+// HandleClick returns the action of the button under (mx, my), if any
+func (p *Panel) HandleClick(mx, my int) Action {
+	if !p.Open {
+		return ActionNone
+	}
+
+	fx, fy := float32(mx), float32(my)
+	for _, button := range p.Buttons {
+		x, y := p.X+button.X, p.Y+button.Y
+		if fx >= x && fx <= x+button.Width && fy >= y && fy <= y+button.Height {
+			return button.Action
+		}
+	}
+
+	return ActionNone
+}
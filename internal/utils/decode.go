@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode reads data loaded from path into v. Paths ending in .yaml/.yml
+// are read as YAML, anything else as JSON. Unknown fields are rejected so
+// typos in data files do not go unnoticed.
+func Decode(path string, data []byte, v any) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		return decoder.Decode(v)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(v)
+	}
+}
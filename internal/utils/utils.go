@@ -1,11 +1,6 @@
 package utils
 
-import (
-	"image"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-)
+import "math"
 
 // CenteredPosition helps work with entities that use centered coordinates.
 // Makes it easy to convert between center and top-left for screen drawing.
@@ -55,20 +50,19 @@ func Max(a, b float32) float32 {
 	return b
 }
 
-// DrawLargeText draws text with actual scaling for better readability
-// This is a shared utility used by HUD, Game, Shop, and GameOver screens
-func DrawLargeText(screen *ebiten.Image, text string, x, y, scale float64) {
-	// Create a temporary image to render text
-	bounds := image.Rect(0, 0, 400, 30)
-	textImg := ebiten.NewImage(bounds.Dx(), bounds.Dy())
-
-	// Draw text on temporary image
-	ebitenutil.DebugPrint(textImg, text)
+// DistanceToSegment returns the distance from point P to the segment AB
+func DistanceToSegment(px, py, ax, ay, bx, by float32) float32 {
+	abx, aby := bx-ax, by-ay
+	lengthSquared := abx*abx + aby*aby
 
-	// Scale and draw the text image to the screen
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(scale, scale)
-	op.GeoM.Translate(x, y)
+	// Project P onto AB and clamp to the segment
+	t := float32(0)
+	if lengthSquared > 0 {
+		t = ((px-ax)*abx + (py-ay)*aby) / lengthSquared
+		t = Max(0, Min(1, t))
+	}
 
-	screen.DrawImage(textImg, op)
+	dx := px - (ax + t*abx)
+	dy := py - (ay + t*aby)
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
 }
@@ -0,0 +1,39 @@
+package wave
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nx23/final-path/internal/utils"
+)
+
+// Load reads a wave schedule file. Files ending in .yaml/.yml are read as
+// YAML, anything else as JSON. Lane and enemy type names are not checked
+// here since they depend on the map and enemy registry; see Validate.
+// Delays and intervals are in seconds, speeds in pixels per second.
+//
+//	{
+//	  "waves": [
+//	    {"groups": [{"type": "basic", "count": 5}]},
+//	    {"groups": [
+//	      {"type": "fast", "count": 6, "interval": 0.5},
+//	      {"type": "armored", "count": 2, "delay": 2, "lane": "north"}
+//	    ]}
+//	  ]
+//	}
+func Load(path string) (Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	var schedule Schedule
+	if err := utils.Decode(path, data, &schedule); err != nil {
+		return Schedule{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := schedule.Validate(nil, nil); err != nil {
+		return Schedule{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return schedule, nil
+}
@@ -0,0 +1,55 @@
+package wave
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadFor reads a schedule file and checks it against the given lanes and
+// enemy types, as main does for every map
+func loadFor(t *testing.T, content string, lanes, types []string) error {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "waves.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := Load(path)
+	if err != nil {
+		return err
+	}
+	return schedule.Validate(lanes, types)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string // Part of the error, "" for none
+	}{
+		{name: "valid", content: `{"waves": [{"groups": [{"type": "fast", "count": 3, "lane": "north"}, {"count": 2}]}]}`},
+		{name: "unknown enemy type", content: `{"waves": [{"groups": [{"type": "dragon", "count": 1}]}]}`, err: `wave 1 group 1: unknown enemy type "dragon"`},
+		{name: "negative count", content: `{"waves": [{"groups": [{"count": 2}]}, {"groups": [{"count": -1}]}]}`, err: "wave 2 group 1: count must be at least 1"},
+		{name: "zero count", content: `{"waves": [{"groups": [{"count": 0}]}]}`, err: "count must be at least 1"},
+		{name: "unknown lane", content: `{"waves": [{"groups": [{"count": 1}, {"count": 1, "lane": "east"}]}]}`, err: `wave 1 group 2: unknown lane "east"`},
+		{name: "negative interval", content: `{"waves": [{"groups": [{"count": 1, "interval": -1}]}]}`, err: "interval and delay cannot be negative"},
+		{name: "negative speed", content: `{"waves": [{"groups": [{"count": 1, "speed": -5}]}]}`, err: "life and speed cannot be negative"},
+		{name: "no groups", content: `{"waves": [{"groups": []}]}`, err: "wave 1 has no groups"},
+		{name: "not JSON", content: `{"waves": [`, err: "waves.json"},
+	}
+
+	lanes := []string{"north", "south"}
+	types := []string{typeBasic, typeFast, typeArmored}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loadFor(t, tt.content, lanes, types)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package wave describes which enemies each wave spawns and when.
+// Waves come from a schedule (a map's "waves" or a standalone schedule
+// file); once the scripted waves run out a procedural wave is generated.
+package wave
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/nx23/final-path/internal/config"
+)
+
+// Enemy kinds used by the procedural waves. They match entity.EnemyKind
+// values; wave only deals in names so maps can embed schedules.
+const (
+	typeBasic        = "basic"
+	typeFast         = "fast"
+	typeArmored      = "armored"
+	typeSwarm        = "swarm"
+	typeRegenerating = "regenerating"
+	typeBoss         = "boss"
+)
+
+// Group is a batch of enemies of one type within a wave
+type Group struct {
+	Type     string  `json:"type" yaml:"type"`         // Enemy kind, defaults to "basic"
+	Count    int     `json:"count" yaml:"count"`       // How many enemies the group spawns
+	Interval float32 `json:"interval" yaml:"interval"` // Seconds between spawns, defaults to the spawn interval constant
+	Delay    float32 `json:"delay" yaml:"delay"`       // Seconds after the wave starts before the first spawn
+	Lane     string  `json:"lane" yaml:"lane"`         // Empty spreads the group across every lane
+	Life     int     `json:"life" yaml:"life"`         // Base life override, 0 uses BaseLife
+	Speed    float32 `json:"speed" yaml:"speed"`       // Base speed override in pixels per second, 0 uses BaseSpeed
+}
+
+// Wave is every group spawned after pressing "Next Wave". Groups run in
+// parallel, each on its own delay and interval.
+type Wave struct {
+	Groups []Group `json:"groups" yaml:"groups"`
+}
+
+// Size returns the number of enemies the wave spawns
+func (w Wave) Size() int {
+	total := 0
+	for _, group := range w.Groups {
+		total += group.Count
+	}
+	return total
+}
+
+// Spawn is one enemy the simulation should create
+type Spawn struct {
+	Time  float32 `json:"time"` // Seconds after the wave started
+	Type  string  `json:"type"`
+	Lane  string  `json:"lane"`
+	Life  int     `json:"life"`
+	Speed float32 `json:"speed"`
+}
+
+// Spawns expands the wave into individual spawns ordered by time.
+// number is the 1-based wave number and difficulty the modifier used for
+// the base stats.
+func (w Wave) Spawns(number, difficulty int) []Spawn {
+	var spawns []Spawn
+	for _, group := range w.Groups {
+		interval := group.Interval
+		if interval == 0 {
+			interval = config.GameConstants.SpawnInterval
+		}
+		kind := group.Type
+		if kind == "" {
+			kind = typeBasic
+		}
+		life := group.Life
+		if life == 0 {
+			life = BaseLife(number, difficulty)
+		}
+		speed := group.Speed
+		if speed == 0 {
+			speed = BaseSpeed(number)
+		}
+
+		for i := 0; i < group.Count; i++ {
+			spawns = append(spawns, Spawn{
+				Time:  group.Delay + float32(i)*interval,
+				Type:  kind,
+				Lane:  group.Lane,
+				Life:  life,
+				Speed: speed,
+			})
+		}
+	}
+
+	sort.SliceStable(spawns, func(i, j int) bool {
+		return spawns[i].Time < spawns[j].Time
+	})
+	return spawns
+}
+
+// Schedule is an ordered list of scripted waves
+type Schedule struct {
+	Waves []Wave `json:"waves" yaml:"waves"`
+}
+
+// Wave returns the given 1-based wave: the scripted one while the
+// schedule lasts, then a procedural one
+func (s Schedule) Wave(number int) Wave {
+	if number >= 1 && number <= len(s.Waves) {
+		return s.Waves[number-1]
+	}
+	return Procedural(number)
+}
+
+// Validate checks counts, timings and overrides. Lane and type names are
+// only checked against lanes and types when those are non-nil.
+func (s Schedule) Validate(lanes, types []string) error {
+	for i, w := range s.Waves {
+		if len(w.Groups) == 0 {
+			return fmt.Errorf("wave %d has no groups", i+1)
+		}
+		for j, group := range w.Groups {
+			if err := group.validate(lanes, types); err != nil {
+				return fmt.Errorf("wave %d group %d: %w", i+1, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (g Group) validate(lanes, types []string) error {
+	if g.Count <= 0 {
+		return errors.New("count must be at least 1")
+	}
+	if g.Interval < 0 || g.Delay < 0 {
+		return errors.New("interval and delay cannot be negative")
+	}
+	if g.Life < 0 || g.Speed < 0 {
+		return errors.New("life and speed cannot be negative")
+	}
+	if g.Lane != "" && lanes != nil && !slices.Contains(lanes, g.Lane) {
+		return fmt.Errorf("unknown lane %q", g.Lane)
+	}
+	if g.Type != "" && types != nil && !slices.Contains(types, g.Type) {
+		return fmt.Errorf("unknown enemy type %q", g.Type)
+	}
+	return nil
+}
+
+// Difficulty returns the difficulty modifier for a wave. It starts at the
+// configured value and goes up by one every rampEvery waves.
+func Difficulty(number, rampEvery int) int {
+	return config.GameConstants.DifficultyModifier + number/max(rampEvery, 1)
+}
+
+// BaseLife returns the base enemy life for a wave at the given difficulty
+// modifier, before type multipliers
+func BaseLife(number, difficulty int) int {
+	return 10 + (1 + (number-1)*2) + (20 * difficulty)
+}
+
+// BaseSpeed returns the base enemy speed for a wave in pixels per second
+// before type multipliers
+func BaseSpeed(number int) float32 {
+	return 120 * (1 + float32(number-1)*0.1)
+}
+
+// Procedural builds a wave from the built-in formula: 3 enemies plus 2
+// per wave, a new enemy type joining the rotation each wave from wave 3,
+// and a boss closing every 10th wave.
+func Procedural(number int) Wave {
+	size := 3 + (number-1)*2
+	interval := config.GameConstants.SpawnInterval
+
+	rotation := []string{typeBasic, typeFast, typeArmored, typeSwarm, typeRegenerating}
+	unlocked := min(max(number-1, 1), len(rotation))
+
+	var w Wave
+	if number%10 == 0 {
+		size--
+		w.Groups = append(w.Groups, Group{Type: typeBoss, Count: 1, Delay: float32(size) * interval, Interval: interval})
+	}
+
+	// Interleave the rotation: type k spawns at k, k+unlocked, k+2*unlocked...
+	for k := 0; k < unlocked && k < size; k++ {
+		count := (size - k + unlocked - 1) / unlocked
+		w.Groups = append(w.Groups, Group{
+			Type:     rotation[k],
+			Count:    count,
+			Delay:    float32(k) * interval,
+			Interval: float32(unlocked) * interval,
+		})
+	}
+
+	return w
+}
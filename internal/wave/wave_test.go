@@ -0,0 +1,59 @@
+package wave
+
+import "testing"
+
+func TestSizeMatchesSpawns(t *testing.T) {
+	schedule := Schedule{Waves: []Wave{
+		{Groups: []Group{{Type: typeBasic, Count: 5}}},
+		{Groups: []Group{{Type: typeFast, Count: 6, Interval: 0.5}, {Type: typeArmored, Count: 2, Delay: 2, Lane: "north"}}},
+	}}
+
+	tests := []struct {
+		name   string
+		number int
+	}{
+		{name: "scripted", number: 1},
+		{name: "scripted with several groups", number: 2},
+		{name: "procedural", number: 3},
+		{name: "procedural with every type", number: 7},
+		{name: "boss", number: 10},
+		{name: "later boss", number: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := schedule.Wave(tt.number)
+			spawns := w.Spawns(tt.number, Difficulty(tt.number, 5))
+			if w.Size() != len(spawns) {
+				t.Errorf("wave %d: Size() = %d, but %d spawns", tt.number, w.Size(), len(spawns))
+			}
+			for i := 1; i < len(spawns); i++ {
+				if spawns[i].Time < spawns[i-1].Time {
+					t.Fatalf("spawn %d at %v comes before spawn %d at %v", i, spawns[i].Time, i-1, spawns[i-1].Time)
+				}
+			}
+		})
+	}
+}
+
+func TestProceduralBoss(t *testing.T) {
+	for _, number := range []int{9, 10, 11, 20} {
+		bosses := 0
+		spawns := Procedural(number).Spawns(number, 1)
+		for _, spawn := range spawns {
+			if spawn.Type == typeBoss {
+				bosses++
+			}
+		}
+		want := 0
+		if number%10 == 0 {
+			want = 1
+		}
+		if bosses != want {
+			t.Errorf("wave %d: got %d bosses, want %d", number, bosses, want)
+		}
+		if len(spawns) != 3+(number-1)*2 {
+			t.Errorf("wave %d: got %d spawns, want %d", number, len(spawns), 3+(number-1)*2)
+		}
+	}
+}
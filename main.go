@@ -1,20 +1,113 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/nx23/final-path/internal/config"
+	"github.com/nx23/final-path/internal/entity"
 	"github.com/nx23/final-path/internal/game"
+	"github.com/nx23/final-path/internal/gamemap"
+	"github.com/nx23/final-path/internal/settings"
+	"github.com/nx23/final-path/internal/shop"
+	"github.com/nx23/final-path/internal/wave"
 )
 
 func main() {
-	g := game.NewGame()
-	
-	ebiten.SetWindowSize(config.Config.Width, config.Config.Height)
+	mapPath := flag.String("map", "", "path to a map file (JSON or YAML) listed first on the map select screen")
+	mapsDir := flag.String("maps", "maps", "directory of map files offered on the map select screen")
+	wavesPath := flag.String("waves", "", "wave schedule file (JSON or YAML) played on every map instead of the map's own waves")
+	configPath := flag.String("config", "", "configuration file (JSON or TOML) overriding the built-in game constants")
+	shopPath := flag.String("shop", "", "shop catalog file (JSON or YAML) sold instead of the built-in items")
+	overrides := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Defaults, then the file, then FINALPATH_* variables, then flags
+	warnings, err := config.Load(*configPath, os.Environ(), overrides)
+	for _, warning := range warnings {
+		log.Print(warning)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	if *shopPath != "" {
+		catalog, err := shop.Load(*shopPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		shop.Default = catalog
+	}
+
+	var maps []gamemap.Map
+	if *mapPath != "" {
+		m, err := gamemap.Load(*mapPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := validateEnemyTypes(m.Waves); err != nil {
+			log.Fatalf("%s: %v", *mapPath, err)
+		}
+		maps = append(maps, m)
+	}
+
+	maps = append(maps, gamemap.BuiltinMaps()...)
+
+	dirMaps, errs := gamemap.LoadDir(*mapsDir)
+	for _, err := range errs {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Skipping map: %v", err)
+		}
+	}
+	for _, m := range dirMaps {
+		if err := validateEnemyTypes(m.Waves); err != nil {
+			log.Printf("Skipping map %q: %v", m.Name, err)
+			continue
+		}
+		maps = append(maps, m)
+	}
+
+	if *wavesPath != "" {
+		schedule, err := wave.Load(*wavesPath)
+		if err == nil {
+			err = validateEnemyTypes(schedule.Waves)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Groups sent down a named lane need that lane on every map
+		for i := range maps {
+			if err := schedule.Validate(maps[i].LaneNames(), nil); err != nil {
+				log.Fatalf("%s: map %q: %v", *wavesPath, maps[i].Name, err)
+			}
+			maps[i].Waves = schedule.Waves
+		}
+	}
+
+	prefs, err := settings.Load()
+	if err != nil {
+		log.Printf("Using default settings: %v", err)
+	}
+
+	g := game.NewGame(prefs, maps...)
+
 	ebiten.SetWindowTitle(config.Config.Title)
-	
+	prefs.Apply()
+
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// validateEnemyTypes checks scripted waves only use registered enemy types
+func validateEnemyTypes(waves []wave.Wave) error {
+	if err := (wave.Schedule{Waves: waves}).Validate(nil, entity.EnemyKindNames()); err != nil {
+		return fmt.Errorf("waves: %w", err)
+	}
+	return nil
+}